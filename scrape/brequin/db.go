@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"log"
+)
+
+func runDbMigrate(ctx context.Context, options *Options, args []string) error {
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	versions, err := database.Migrate(ctx)
+	for _, version := range versions {
+		log.Println("Applied migration " + version)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(versions) == 0 {
+		log.Println("Schema is up to date")
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/brequin/brequin/scrape/courses"
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/failures"
+)
+
+var errNoFailuresDir = errors.New("No failures directory; set --failures-dir or SCRAPE_FAILURES_DIR")
+
+func listSnapshots(options *Options) ([]failures.Snapshot, error) {
+	if !options.Snapshots.Enabled() {
+		return nil, errNoFailuresDir
+	}
+
+	snapshots, err := options.Snapshots.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []failures.Snapshot
+	for _, snapshot := range snapshots {
+		if options.Filter.MatchQuarter(db.Quarter{Code: snapshot.QuarterCode}) && options.Filter.MatchSubjectArea(db.SubjectArea{Code: snapshot.SubjectAreaCode}) {
+			filtered = append(filtered, snapshot)
+		}
+	}
+	return filtered, nil
+}
+
+func runFailuresList(ctx context.Context, options *Options, args []string) error {
+	snapshots, err := listSnapshots(options)
+	if err != nil {
+		return err
+	}
+
+	for _, snapshot := range snapshots {
+		fmt.Printf("%v\t%v\t%v\t%v\t%v\n", snapshot.QuarterCode, snapshot.SubjectAreaCode, snapshot.CatalogNumber, snapshot.SavedAt.Format(time.RFC3339), snapshot.Error)
+	}
+	return nil
+}
+
+// Subject areas are read from the database when a connection string is set,
+// since requisites naming other subject areas only parse as courses with them
+func runFailuresReplay(ctx context.Context, options *Options, args []string) error {
+	snapshots, err := listSnapshots(options)
+	if err != nil {
+		return err
+	}
+
+	if options.Dsn != "" {
+		database, err := options.OpenDatabase(ctx)
+		if err != nil {
+			return err
+		}
+		subjectAreas, err := database.ListSubjectAreas(ctx)
+		database.Close()
+		if err != nil {
+			return err
+		}
+		courses.SetSubjectAreas(subjectAreas)
+	} else {
+		log.Println("No database connection string; requisites will not be recognized as courses")
+	}
+
+	var fixedCount int
+	for _, snapshot := range snapshots {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		replayed, err := courses.Replay(snapshot)
+		status := "fixed"
+		if err != nil {
+			status = "failed"
+		} else {
+			fixedCount++
+		}
+		fmt.Printf("%v\t%v\t%v\t%v\t%v\t%v\n", snapshot.QuarterCode, snapshot.SubjectAreaCode, snapshot.CatalogNumber, status, replayed.Expression, replayed.Error)
+
+		if err == nil && options.Prune {
+			if err := options.Snapshots.Remove(snapshot); err != nil {
+				return err
+			}
+		}
+	}
+
+	log.Printf("%v of %v failures now parse\n", fixedCount, len(snapshots))
+	if fixedCount < len(snapshots) {
+		return fmt.Errorf("%v failures still do not parse", len(snapshots)-fixedCount)
+	}
+	return nil
+}
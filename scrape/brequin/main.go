@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/brequin/brequin/scrape/report"
+)
+
+const (
+	exitFailure        = 1
+	exitUsage          = 2
+	exitPartialFailure = 3
+)
+
+type Command struct {
+	Name  string
+	Usage string
+	Run   func(ctx context.Context, options *Options, args []string) error
+
+	Daemon bool // Runs until interrupted, applying --timeout to each run instead
+}
+
+var commands = []Command{
+	{Name: "scrape quarters", Usage: "scrape the quarters listed by the schedule of classes", Run: runScrapeQuarters},
+	{Name: "scrape subjects", Usage: "scrape the subject areas offered in each quarter", Run: runScrapeSubjects},
+	{Name: "scrape courses", Usage: "scrape courses and requisites for each quarter and subject area", Run: runScrapeCourses},
+	{Name: "scrape details", Usage: "scrape course names, units, levels and descriptions", Run: runScrapeDetails},
+	{Name: "pipeline run", Usage: "run the given stages, or all, after the stages they depend on", Run: runPipeline},
+	{Name: "failures list", Usage: "list saved failure snapshots", Run: runFailuresList},
+	{Name: "failures replay", Usage: "parse saved failure snapshots again with the current parser", Run: runFailuresReplay},
+	{Name: "serve scheduler", Usage: "scrape each stage on its schedule, serving /healthz and /status", Run: runServeScheduler, Daemon: true},
+	{Name: "db migrate", Usage: "apply pending schema migrations", Run: runDbMigrate},
+	{Name: "query quarters", Usage: "list scraped quarters", Run: runQueryQuarters},
+	{Name: "query subjects", Usage: "list scraped subject areas, per quarter if --quarter is given", Run: runQuerySubjects},
+	{Name: "query eligibility", Usage: "list grading, fees, impacted status and restrictions of the given catalog numbers' offerings", Run: runQueryEligibility},
+	{Name: "query equivalents", Usage: "list the multiple-listed and concurrently scheduled equivalents of the given catalog numbers", Run: runQueryEquivalents},
+	{Name: "query finals", Usage: "list the final exams of the given sections and fail if any conflict", Run: runQueryFinals},
+	{Name: "query instructors", Usage: "list who taught the given catalog numbers with --subject, or what the given instructors taught", Run: runQueryInstructors},
+	{Name: "query offerings", Usage: "list the quarters the given catalog numbers were offered in, or the courses offered per quarter, by --ge", Run: runQueryOfferings},
+	{Name: "query requisites", Usage: "list the requisite graph as of --quarter, or when the given catalog numbers' requisites held", Run: runQueryRequisites},
+	{Name: "query sections", Usage: "list scraped sections and their meetings per quarter and subject area", Run: runQuerySections},
+	{Name: "query enrollment", Usage: "list enrollment snapshots and fill rates per section, between --from and --until", Run: runQueryEnrollment},
+	{Name: "query runs", Usage: "list the most recent scrape runs", Run: runQueryRuns},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: brequin <command> <subcommand> [flags] [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Flags may also follow arguments; everything after -- is an argument.")
+	fmt.Fprintln(os.Stderr)
+	for _, command := range commands {
+		fmt.Fprintf(os.Stderr, "  %-18v %v\n", command.Name, command.Usage)
+	}
+}
+
+// Unlike flagSet.Parse, keeps reading flags after the first argument, so that
+// "query offerings 31 --subject MATH" does not take --subject as a catalog number
+func parseArgs(flagSet *flag.FlagSet, arguments []string) []string {
+	var args []string
+	for {
+		flagSet.Parse(arguments)
+		remaining := flagSet.Args()
+		consumed := arguments[:len(arguments)-len(remaining)]
+		if len(consumed) > 0 && consumed[len(consumed)-1] == "--" {
+			return append(args, remaining...)
+		}
+		if len(remaining) == 0 {
+			return args
+		}
+		args = append(args, remaining[0])
+		arguments = remaining[1:]
+	}
+}
+
+func main() {
+	if len(os.Args) < 3 {
+		usage()
+		os.Exit(exitUsage)
+	}
+
+	name := os.Args[1] + " " + os.Args[2]
+	for _, command := range commands {
+		if command.Name != name {
+			continue
+		}
+
+		options := NewOptions()
+		flagSet := flag.NewFlagSet("brequin "+name, flag.ExitOnError)
+		options.RegisterFlags(flagSet)
+		args := parseArgs(flagSet, os.Args[3:])
+
+		closeLog, err := options.SetupLogging()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// In-flight units finish or roll back once interrupted
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		if options.Timeout > 0 && !command.Daemon {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+			defer cancel()
+		}
+
+		ctx, options.Errors = report.NewCollector(ctx, options.ErrorPolicy())
+
+		err = command.Run(ctx, options, args)
+		stop()
+
+		if options.Errors.Len() > 0 {
+			log.Print(options.Errors.Summary())
+		}
+		if err != nil {
+			log.Println(err)
+		}
+		closeLog()
+
+		switch {
+		case err != nil:
+			os.Exit(exitFailure)
+		case options.Errors.Len() > 0:
+			os.Exit(exitPartialFailure)
+		}
+		return
+	}
+
+	usage()
+	os.Exit(exitUsage)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"time"
+
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/failures"
+	"github.com/brequin/brequin/scrape/filter"
+	"github.com/brequin/brequin/scrape/progress"
+	"github.com/brequin/brequin/scrape/report"
+	"github.com/brequin/brequin/scrape/soc"
+)
+
+type Options struct {
+	Dsn     string
+	LogFile string
+	Quiet   bool
+	Filter  filter.Filter
+	Client  *soc.Client
+
+	Resume      bool
+	RetryFailed bool
+	Timeout     time.Duration
+	FailFast    bool
+	Errors      *report.Collector
+	Incremental bool
+	RefreshGe   bool
+	DryRun      bool
+	OutputFile  string
+	Snapshots   failures.Snapshots
+	Prune       bool
+	Listen      string
+	Schedules   map[string]time.Duration
+	Jitter      time.Duration
+	From        time.Time
+	Until       time.Time
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Dsn:       os.Getenv("DATABASE_CONNECTION_STRING"),
+		Client:    soc.NewClientFromEnv(),
+		Snapshots: failures.NewSnapshotsFromEnv(),
+		Listen:    ":8080",
+		Schedules: maps.Clone(defaultSchedules),
+		Jitter:    10 * time.Minute,
+	}
+}
+
+func (o *Options) RegisterFlags(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&o.Dsn, "dsn", o.Dsn, "Postgres connection string, sqlite:PATH or memory:; defaults to $DATABASE_CONNECTION_STRING")
+	flagSet.IntVar(&o.Client.Limiter.MaxInFlight, "concurrency", o.Client.Limiter.MaxInFlight, "maximum in-flight requests per host")
+	flagSet.Float64Var(&o.Client.Limiter.RequestsPerSecond, "rate", o.Client.Limiter.RequestsPerSecond, "maximum requests per second per host")
+	flagSet.StringVar(&o.LogFile, "log-file", o.LogFile, "append logs to this file instead of stderr")
+	flagSet.BoolVar(&o.Quiet, "quiet", o.Quiet, "discard logs")
+	flagSet.DurationVar(&o.Timeout, "timeout", o.Timeout, "cancel the whole run after this long, e.g. 2h")
+	flagSet.BoolVar(&o.FailFast, "fail-fast", o.FailFast, "stop at the first failure instead of reporting all failures at the end")
+	flagSet.BoolVar(&o.Incremental, "incremental", o.Incremental, "skip courses whose requisites are unchanged since the last run")
+	flagSet.BoolVar(&o.RefreshGe, "refresh-ge", o.RefreshGe, "with --incremental, search GE categories even of subject areas listing no new courses")
+	flagSet.BoolVar(&o.Resume, "resume", o.Resume, "skip units completed since the last run that scraped every unit")
+	flagSet.BoolVar(&o.RetryFailed, "retry-failed", o.RetryFailed, "scrape only units that failed since the last run that scraped every unit")
+	flagSet.BoolVar(&o.DryRun, "dry-run", o.DryRun, "write scraped rows as JSON Lines instead of to the database")
+	flagSet.Func("output", "dry run, writing rows in this format; only jsonl is supported", func(value string) error {
+		if value != "jsonl" {
+			return fmt.Errorf("Unsupported output format %q", value)
+		}
+		o.DryRun = true
+		return nil
+	})
+	flagSet.StringVar(&o.OutputFile, "output-file", o.OutputFile, "write dry run output to this file instead of stdout")
+	flagSet.StringVar(&o.Snapshots.Dir, "failures-dir", o.Snapshots.Dir, "save pages whose requisites fail to parse here; defaults to $SCRAPE_FAILURES_DIR")
+	flagSet.BoolVar(&o.Prune, "prune", o.Prune, "remove failure snapshots that replay successfully")
+	flagSet.StringVar(&o.Listen, "listen", o.Listen, "address to serve scheduler health and status on")
+	flagSet.Var(scheduleFlag{&o.Schedules}, "schedule", "stage=interval to override a stage's schedule, e.g. courses=6h; 0 disables it")
+	flagSet.DurationVar(&o.Jitter, "jitter", o.Jitter, "maximum random delay added to each scheduled run")
+	flagSet.Func("from", "query enrollment observed on or after this date, e.g. 2024-08-01", dateFlag(&o.From))
+	flagSet.Func("until", "query enrollment observed before this date; defaults to now", dateFlag(&o.Until))
+	o.Filter.RegisterFlags(flagSet)
+	o.Client.RegisterFlags(flagSet)
+}
+
+func dateFlag(t *time.Time) func(string) error {
+	return func(value string) error {
+		date, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+		if err != nil {
+			return fmt.Errorf("Invalid date %q; expected YYYY-MM-DD", value)
+		}
+		*t = date
+		return nil
+	}
+}
+
+// The returned function closes the log file, if any
+func (o *Options) SetupLogging() (func(), error) {
+	if o.Quiet {
+		log.SetOutput(io.Discard)
+		return func() {}, nil
+	}
+	if o.LogFile == "" {
+		return func() {}, nil
+	}
+
+	file, err := os.OpenFile(o.LogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	log.SetOutput(file)
+	return func() { file.Close() }, nil
+}
+
+// Dry runs read from an empty in-memory store when no connection string is set
+func (o *Options) OpenDatabase(ctx context.Context) (db.Store, error) {
+	var database db.Store
+	switch {
+	case o.Dsn != "":
+		var err error
+		if database, err = db.Open(ctx, o.Dsn); err != nil {
+			return nil, err
+		}
+	case o.DryRun:
+		database = db.NewMemory()
+	default:
+		return nil, errors.New("No database connection string; set --dsn or DATABASE_CONNECTION_STRING")
+	}
+
+	if !o.DryRun {
+		return database, nil
+	}
+
+	output, err := db.OpenOutput(o.OutputFile)
+	if err != nil {
+		database.Close()
+		return nil, err
+	}
+	return &db.DryRun{Reader: database, Output: output}, nil
+}
+
+func (o *Options) ProgressMode() progress.Mode {
+	switch {
+	case o.RetryFailed:
+		return progress.ModeRetryFailed
+	case o.Resume:
+		return progress.ModeResume
+	default:
+		return progress.ModeAll
+	}
+}
+
+// Units retried after failing may have failed on a GE search, so retries search again
+func (o *Options) RefreshGeCategories() bool {
+	return o.RefreshGe || o.RetryFailed
+}
+
+// Recorded with each scrape run
+func (o *Options) Parameters() map[string]any {
+	return map[string]any{
+		"quarters":     o.Filter.QuarterCodes,
+		"subjects":     o.Filter.SubjectAreaCodes,
+		"since":        o.Filter.Since,
+		"current_only": o.Filter.CurrentOnly,
+		"concurrency":  o.Client.Limiter.MaxInFlight,
+		"rate":         o.Client.Limiter.RequestsPerSecond,
+		"offline":      o.Client.Offline,
+		"cache_dir":    o.Client.Cache.Dir,
+		"resume":       o.Resume,
+		"retry_failed": o.RetryFailed,
+		"fail_fast":    o.FailFast,
+		"incremental":  o.Incremental,
+		"refresh_ge":   o.RefreshGeCategories(),
+		"dry_run":      o.DryRun,
+		"failures_dir": o.Snapshots.Dir,
+		"soc_base_url": o.Client.SocBaseUrl,
+		"api_base_url": o.Client.ApiBaseUrl,
+	}
+}
+
+func (o *Options) ErrorPolicy() report.Policy {
+	if o.FailFast {
+		return report.PolicyFailFast
+	}
+	return report.PolicyBestEffort
+}
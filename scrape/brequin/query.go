@@ -0,0 +1,444 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brequin/brequin/scrape/courses"
+	"github.com/brequin/brequin/scrape/db"
+)
+
+func runQueryQuarters(ctx context.Context, options *Options, args []string) error {
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	quarters, err := database.ListQuarters(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, quarter := range options.Filter.Quarters(quarters) {
+		fmt.Printf("%v\t%v\n", quarter.Code, quarter.Name)
+	}
+	return nil
+}
+
+func runQuerySubjects(ctx context.Context, options *Options, args []string) error {
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if !options.Filter.FiltersQuarters() {
+		subjectAreas, err := database.ListSubjectAreas(ctx)
+		if err != nil {
+			return err
+		}
+		for _, subjectArea := range options.Filter.SubjectAreas(subjectAreas) {
+			fmt.Printf("%v\t%v\n", subjectArea.Code, subjectArea.Name)
+		}
+		return nil
+	}
+
+	quarters, err := database.ListQuarters(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, quarter := range options.Filter.Quarters(quarters) {
+		subjectAreas, err := database.ListQuarterSubjectAreas(ctx, quarter)
+		if err != nil {
+			return err
+		}
+		for _, subjectArea := range options.Filter.SubjectAreas(subjectAreas) {
+			fmt.Printf("%v\t%v\t%v\n", quarter.Code, subjectArea.Code, subjectArea.Name)
+		}
+	}
+	return nil
+}
+
+func runQueryRuns(ctx context.Context, options *Options, args []string) error {
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	runs, err := database.ListScrapeRuns(ctx, 20)
+	if err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		finishedAt := "running"
+		if run.FinishedAt != nil {
+			finishedAt = run.FinishedAt.Format(time.RFC3339)
+		}
+		counts, err := json.Marshal(run.Counts)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%v\t%v\t%v\t%v\t%v\t%s\t%v\t%v\n", run.Id, run.Stage, run.StartedAt.Format(time.RFC3339), finishedAt, run.GitRevision, counts, run.Error, strings.Join(run.Changed, ", "))
+	}
+	return nil
+}
+
+func runQuerySections(ctx context.Context, options *Options, args []string) error {
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	quarters, err := database.ListQuarters(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, quarter := range options.Filter.Quarters(quarters) {
+		subjectAreas, err := database.ListQuarterSubjectAreas(ctx, quarter)
+		if err != nil {
+			return err
+		}
+		for _, subjectArea := range options.Filter.SubjectAreas(subjectAreas) {
+			sections, err := database.ListSections(ctx, quarter.Code, subjectArea.Code)
+			if err != nil {
+				return err
+			}
+			meetings, err := database.ListMeetings(ctx, quarter.Code, subjectArea.Code)
+			if err != nil {
+				return err
+			}
+
+			sectionMeetings := make(map[string][]db.Meeting)
+			for _, meeting := range meetings {
+				sectionMeetings[meeting.SectionId] = append(sectionMeetings[meeting.SectionId], meeting)
+			}
+
+			for _, section := range sections {
+				fmt.Printf("%v\t%v\t%v\t%v %v\t%v\t%v\n", quarter.Code, subjectArea.Code, section.CatalogNumber, section.Activity, section.Number, section.Units, section.Instructors)
+				for _, meeting := range sectionMeetings[section.Id] {
+					fmt.Printf("\t%v\t%v-%v\t%v\n", meeting.Days, meeting.StartTime, meeting.EndTime, meeting.Location)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func runQueryEnrollment(ctx context.Context, options *Options, args []string) error {
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	until := options.Until
+	if until.IsZero() {
+		until = time.Now()
+	}
+
+	quarters, err := database.ListQuarters(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, quarter := range options.Filter.Quarters(quarters) {
+		subjectAreas, err := database.ListQuarterSubjectAreas(ctx, quarter)
+		if err != nil {
+			return err
+		}
+		for _, subjectArea := range options.Filter.SubjectAreas(subjectAreas) {
+			snapshots, err := database.ListEnrollmentSnapshots(ctx, quarter.Code, subjectArea.Code, options.From, until)
+			if err != nil {
+				return err
+			}
+			for _, snapshot := range snapshots {
+				fmt.Printf("%v\t%v\t%v\t%v/%v\t%v/%v\t%.0f%%\n", snapshot.SectionId, snapshot.ObservedAt.Format(time.RFC3339), snapshot.Status, snapshot.Enrolled, snapshot.Capacity, snapshot.Waitlisted, snapshot.WaitlistCapacity, 100*snapshot.FillRate())
+			}
+		}
+	}
+	return nil
+}
+
+// Catalog numbers may be given as arguments, with --subject, to list the quarters each was offered in;
+// otherwise the courses offered in each quarter are listed with their GE categories, narrowed by --ge
+func runQueryOfferings(ctx context.Context, options *Options, args []string) error {
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if len(args) > 0 {
+		if len(options.Filter.SubjectAreaCodes) == 0 {
+			return errors.New("Catalog numbers need a --subject")
+		}
+		for _, subjectAreaCode := range options.Filter.SubjectAreaCodes {
+			for _, catalogNumber := range args {
+				quarters, err := database.ListCourseOfferings(ctx, db.Course{SubjectAreaCode: subjectAreaCode, CatalogNumber: catalogNumber})
+				if err != nil {
+					return err
+				}
+				var quarterCodes []string
+				for _, quarter := range options.Filter.Quarters(quarters) {
+					quarterCodes = append(quarterCodes, quarter.Code)
+				}
+				fmt.Printf("%v\t%v\t%v\n", subjectAreaCode, catalogNumber, strings.Join(quarterCodes, " "))
+			}
+		}
+		return nil
+	}
+
+	quarters, err := database.ListQuarters(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, quarter := range options.Filter.Quarters(quarters) {
+		subjectAreas, err := database.ListQuarterSubjectAreas(ctx, quarter)
+		if err != nil {
+			return err
+		}
+		for _, subjectArea := range options.Filter.SubjectAreas(subjectAreas) {
+			courses, err := listOfferedCourses(ctx, database, quarter, subjectArea, options.Filter.GeCategories)
+			if err != nil {
+				return err
+			}
+			courseGeCategories, err := database.ListCourseGeCategories(ctx, quarter, subjectArea)
+			if err != nil {
+				return err
+			}
+			geCategoriesByCourse := make(map[string][]db.CourseGeCategory)
+			for _, c := range courseGeCategories {
+				geCategoriesByCourse[c.CatalogNumber] = append(geCategoriesByCourse[c.CatalogNumber], c)
+			}
+
+			for _, course := range courses {
+				geCategories := geCategoriesByCourse[course.CatalogNumber]
+				var geCodes []string
+				for _, c := range geCategories {
+					geCodes = append(geCodes, c.Foundation+"/"+c.Category)
+				}
+				fmt.Printf("%v\t%v\t%v\t%v\n", quarter.Code, course.SubjectAreaCode, course.CatalogNumber, strings.Join(geCodes, " "))
+			}
+		}
+	}
+	return nil
+}
+
+// Courses offered in any of the given GE foundations or categories, such as "SI" or "SI/LS", or every offered course if none are given
+func listOfferedCourses(ctx context.Context, database db.Reader, quarter db.Quarter, subjectArea db.SubjectArea, geCategories []string) ([]db.Course, error) {
+	if len(geCategories) == 0 {
+		return database.ListQuarterCourses(ctx, quarter, subjectArea)
+	}
+
+	seen := make(map[string]bool)
+	var courses []db.Course
+	for _, geCategory := range geCategories {
+		foundation, category, _ := strings.Cut(geCategory, "/")
+		geCourses, err := database.ListGeQuarterCourses(ctx, quarter, subjectArea, foundation, category)
+		if err != nil {
+			return nil, err
+		}
+		for _, course := range geCourses {
+			if !seen[course.CatalogNumber] {
+				seen[course.CatalogNumber] = true
+				courses = append(courses, course)
+			}
+		}
+	}
+	sort.Slice(courses, func(i, j int) bool {
+		return courses[i].CatalogNumber < courses[j].CatalogNumber
+	})
+	return courses, nil
+}
+
+// Catalog numbers may be given as arguments, with --subject, to list when each of their requisite relations held;
+// otherwise the requisite graph as of the one --quarter given is listed
+func runQueryRequisites(ctx context.Context, options *Options, args []string) error {
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if len(args) > 0 {
+		if len(options.Filter.SubjectAreaCodes) == 0 {
+			return errors.New("Catalog numbers need a --subject")
+		}
+		for _, subjectAreaCode := range options.Filter.SubjectAreaCodes {
+			for _, catalogNumber := range args {
+				course := db.Course{SubjectAreaCode: subjectAreaCode, CatalogNumber: catalogNumber, NodeId: db.ValueNodeId(subjectAreaCode, catalogNumber)}
+				versions, err := database.ListRelationHistory(ctx, course)
+				if err != nil {
+					return err
+				}
+				for _, version := range versions {
+					fmt.Printf("%v\t%v\t%v\t%v\t%v\n", course.NodeId, version.SourceId, version.TargetId, version.ValidFrom, version.ValidTo)
+				}
+			}
+		}
+		return nil
+	}
+
+	if len(options.Filter.QuarterCodes) != 1 {
+		return errors.New("Give one --quarter to list the requisite graph as of, or catalog numbers with --subject")
+	}
+	if db.QuarterRank(options.Filter.QuarterCodes[0]) == "" {
+		return fmt.Errorf("%q is not a quarter code", options.Filter.QuarterCodes[0])
+	}
+
+	relations, err := database.ListRelationsAsOf(ctx, options.Filter.QuarterCodes[0])
+	if err != nil {
+		return err
+	}
+
+	for _, relation := range relations {
+		fmt.Printf("%v\t%v\t%v\t%v\t%v\t%v\n", relation.SourceId, relation.TargetId, db.FormatOptionalBoolean(relation.Enforced), db.FormatOptionalBoolean(relation.Prereq), db.FormatOptionalBoolean(relation.Coreq), db.FormatOptionalString(relation.MinimumGrade))
+	}
+	return nil
+}
+
+// With --subject, arguments are catalog numbers and the instructors of each course are listed per quarter;
+// otherwise arguments are instructor names, e.g. "Smith, J.", and the courses each taught are listed
+func runQueryInstructors(ctx context.Context, options *Options, args []string) error {
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	var teachings []db.Teaching
+	for _, arg := range args {
+		if len(options.Filter.SubjectAreaCodes) > 0 {
+			for _, subjectAreaCode := range options.Filter.SubjectAreaCodes {
+				courseTeachings, err := database.ListCourseTeachings(ctx, db.Course{SubjectAreaCode: subjectAreaCode, CatalogNumber: arg})
+				if err != nil {
+					return err
+				}
+				teachings = append(teachings, courseTeachings...)
+			}
+			continue
+		}
+
+		instructor, ok := courses.ParseInstructor(arg)
+		if !ok {
+			return fmt.Errorf("%q is not an instructor name", arg)
+		}
+		instructorTeachings, err := database.ListInstructorTeachings(ctx, instructor.Id)
+		if err != nil {
+			return err
+		}
+		teachings = append(teachings, instructorTeachings...)
+	}
+
+	for _, teaching := range teachings {
+		if !options.Filter.MatchQuarter(db.Quarter{Code: teaching.QuarterCode}) {
+			continue
+		}
+		fmt.Printf("%v\t%v\t%v\t%v\n", teaching.QuarterCode, teaching.SubjectAreaCode, teaching.CatalogNumber, teaching.InstructorName)
+	}
+	return nil
+}
+
+// Arguments are section ids, as listed by query enrollment
+func runQueryFinals(ctx context.Context, options *Options, args []string) error {
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	finalExams, err := database.ListFinalExams(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	for _, finalExam := range finalExams {
+		fmt.Printf("%v\t%v\t%v-%v\t%v\n", finalExam.SectionId, finalExam.Date, finalExam.StartTime, finalExam.EndTime, finalExam.Location)
+	}
+
+	conflicts := db.FindFinalExamConflicts(finalExams)
+	for _, conflict := range conflicts {
+		fmt.Printf("Conflict: %v and %v on %v\n", conflict.First.SectionId, conflict.Second.SectionId, conflict.First.Date)
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%v final exam conflicts", len(conflicts))
+	}
+	return nil
+}
+
+// Arguments are catalog numbers, with --subject; lists what each offering's class detail page restricts or requires
+func runQueryEligibility(ctx context.Context, options *Options, args []string) error {
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if len(options.Filter.SubjectAreaCodes) == 0 {
+		return errors.New("Catalog numbers need a --subject")
+	}
+
+	for _, subjectAreaCode := range options.Filter.SubjectAreaCodes {
+		for _, catalogNumber := range args {
+			quarters, err := database.ListCourseOfferings(ctx, db.Course{SubjectAreaCode: subjectAreaCode, CatalogNumber: catalogNumber})
+			if err != nil {
+				return err
+			}
+			for _, quarter := range options.Filter.Quarters(quarters) {
+				offeringDetails, exists, err := database.GetOfferingDetails(ctx, db.QuarterCourse{QuarterCode: quarter.Code, SubjectAreaCode: subjectAreaCode, CatalogNumber: catalogNumber})
+				if err != nil {
+					return err
+				}
+				if !exists {
+					continue
+				}
+
+				fmt.Printf("%v\t%v\t%v\t%v\t$%.2f\timpacted=%v\n", quarter.Code, subjectAreaCode, catalogNumber, offeringDetails.GradingBasis, float64(offeringDetails.FeeCents)/100, offeringDetails.Impacted)
+				for _, restriction := range offeringDetails.Restrictions {
+					fmt.Printf("\t%v\t%v\n", restriction.Kind, restriction.Description)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Arguments are catalog numbers, with --subject; lists the courses whose completion satisfies requisites on each
+func runQueryEquivalents(ctx context.Context, options *Options, args []string) error {
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	if len(options.Filter.SubjectAreaCodes) == 0 {
+		return errors.New("Catalog numbers need a --subject")
+	}
+
+	for _, subjectAreaCode := range options.Filter.SubjectAreaCodes {
+		for _, catalogNumber := range args {
+			equivalents, err := database.ListCourseEquivalents(ctx, db.Course{SubjectAreaCode: subjectAreaCode, CatalogNumber: catalogNumber})
+			if err != nil {
+				return err
+			}
+			var names []string
+			for _, equivalent := range equivalents {
+				names = append(names, equivalent.SubjectAreaCode+" "+equivalent.CatalogNumber)
+			}
+			fmt.Printf("%v\t%v\t%v\n", subjectAreaCode, catalogNumber, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/brequin/brequin/scrape/courses"
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/details"
+	"github.com/brequin/brequin/scrape/pipeline"
+	"github.com/brequin/brequin/scrape/progress"
+	"github.com/brequin/brequin/scrape/quarters"
+	"github.com/brequin/brequin/scrape/subjects"
+)
+
+var errDryRunInput = errors.New("This command reads its input from the database; set --dsn or dry run the pipeline instead")
+
+// Reports errors flushing dry run output unless the command already failed
+func closeDatabase(database db.Store, err *error) {
+	if closeErr := database.Close(); closeErr != nil && *err == nil {
+		*err = closeErr
+	}
+}
+
+func runScrapeQuarters(ctx context.Context, options *Options, args []string) (err error) {
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(database, &err)
+
+	unlock, err := pipeline.Lock(ctx, database)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return pipeline.WithScrapeRun(ctx, database, options.Errors, "quarters", options.Parameters(), func(runDatabase db.Store) (db.RunSummary, error) {
+		quarters, err := quarters.Run(ctx, options.Client, runDatabase)
+		return db.RunSummary{Counts: map[string]int{"quarters": len(quarters)}}, err
+	})
+}
+
+func runScrapeSubjects(ctx context.Context, options *Options, args []string) (err error) {
+	if options.DryRun && options.Dsn == "" {
+		return errDryRunInput
+	}
+
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(database, &err)
+
+	unlock, err := pipeline.Lock(ctx, database)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	quarters, err := database.ListQuarters(ctx)
+	if err != nil {
+		return err
+	}
+
+	tracker, err := progress.Load(ctx, database, "subjects", options.ProgressMode(), &options.Filter)
+	if err != nil {
+		return err
+	}
+
+	return pipeline.WithScrapeRun(ctx, database, options.Errors, "subjects", options.Parameters(), func(runDatabase db.Store) (db.RunSummary, error) {
+		quarterSubjectAreas, err := subjects.Run(ctx, options.Client, runDatabase, options.Filter.Quarters(quarters), tracker, options.Errors)
+		return db.RunSummary{Counts: pipeline.SubjectsCounts(quarterSubjectAreas)}, err
+	})
+}
+
+func runScrapeCourses(ctx context.Context, options *Options, args []string) (err error) {
+	if options.DryRun && options.Dsn == "" {
+		return errDryRunInput
+	}
+
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(database, &err)
+
+	unlock, err := pipeline.Lock(ctx, database)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	input, err := courses.LoadInput(ctx, database, &options.Filter)
+	if err != nil {
+		return err
+	}
+
+	tracker, err := progress.Load(ctx, database, "courses", options.ProgressMode(), &options.Filter)
+	if err != nil {
+		return err
+	}
+
+	return pipeline.WithScrapeRun(ctx, database, options.Errors, "courses", options.Parameters(), func(runDatabase db.Store) (db.RunSummary, error) {
+		counts, err := courses.Run(ctx, options.Client, runDatabase, input, &options.Filter, tracker, options.Errors, options.Snapshots, options.Incremental, options.RefreshGeCategories())
+		return db.RunSummary{Counts: counts.Map(), Changed: counts.ChangedUnits}, err
+	})
+}
+
+func runScrapeDetails(ctx context.Context, options *Options, args []string) (err error) {
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(database, &err)
+
+	unlock, err := pipeline.Lock(ctx, database)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tracker, err := progress.Load(ctx, database, "details", options.ProgressMode(), &options.Filter)
+	if err != nil {
+		return err
+	}
+
+	return pipeline.WithScrapeRun(ctx, database, options.Errors, "details", options.Parameters(), func(runDatabase db.Store) (db.RunSummary, error) {
+		count, err := details.Run(ctx, options.Client, runDatabase, &options.Filter, tracker, options.Errors)
+		return db.RunSummary{Counts: map[string]int{"courses_details": count}}, err
+	})
+}
+
+// Stages may be given as arguments; all stages run by default
+func runPipeline(ctx context.Context, options *Options, args []string) (err error) {
+	var names []string
+	for _, arg := range args {
+		names = append(names, strings.Split(arg, ",")...)
+	}
+	if len(names) == 0 {
+		for _, stage := range pipeline.Stages {
+			names = append(names, stage.Name)
+		}
+	}
+
+	plan, err := pipeline.Plan(names)
+	if err != nil {
+		return err
+	}
+
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer closeDatabase(database, &err)
+
+	// Run takes the lock itself
+	state := pipeline.NewState(options.Client, database, &options.Filter, options.ProgressMode(), options.Parameters(), options.Errors)
+	state.Incremental = options.Incremental
+	state.RefreshGe = options.RefreshGeCategories()
+	state.Snapshots = options.Snapshots
+	return pipeline.Run(ctx, state, plan)
+}
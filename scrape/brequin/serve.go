@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/report"
+	"github.com/brequin/brequin/scrape/scheduler"
+)
+
+var defaultSchedules = map[string]time.Duration{
+	"quarters": 24 * time.Hour,
+	"subjects": 24 * time.Hour,
+	"courses":  6 * time.Hour,
+	"details":  7 * 24 * time.Hour,
+}
+
+var scheduledCommands = map[string]func(ctx context.Context, options *Options, args []string) error{
+	"quarters": runScrapeQuarters,
+	"subjects": runScrapeSubjects,
+	"courses":  runScrapeCourses,
+	"details":  runScrapeDetails,
+}
+
+// Accepts stage=interval pairs, repeated or comma separated; a zero interval disables the stage
+type scheduleFlag struct {
+	schedules *map[string]time.Duration
+}
+
+func (f scheduleFlag) String() string {
+	if f.schedules == nil {
+		return ""
+	}
+	var parts []string
+	for stage, interval := range *f.schedules {
+		parts = append(parts, stage+"="+interval.String())
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+func (f scheduleFlag) Set(value string) error {
+	for _, part := range strings.Split(value, ",") {
+		stage, rawInterval, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			return fmt.Errorf("Invalid schedule %q, expected stage=interval", part)
+		}
+		if _, exists := scheduledCommands[stage]; !exists {
+			return fmt.Errorf("Unknown stage %v", stage)
+		}
+		interval, err := time.ParseDuration(rawInterval)
+		if err != nil {
+			return err
+		}
+		(*f.schedules)[stage] = interval
+	}
+	return nil
+}
+
+// Each scheduled run gets its own options, so failures are reported per run.
+// Courses are scraped for the current and upcoming quarters unless a quarter filter is given.
+func scheduledJob(options *Options, stage string, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     stage,
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			jobOptions := *options
+			if stage == "courses" && !jobOptions.Filter.FiltersQuarters() {
+				jobOptions.Filter.Since = db.CurrentQuarterCode(time.Now())
+			}
+
+			if jobOptions.Timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, jobOptions.Timeout)
+				defer cancel()
+			}
+			ctx, jobOptions.Errors = report.NewCollector(ctx, jobOptions.ErrorPolicy())
+
+			err := scheduledCommands[stage](ctx, &jobOptions, nil)
+			if jobOptions.Errors.Len() > 0 {
+				log.Print(jobOptions.Errors.Summary())
+			}
+			if err != nil {
+				return err
+			}
+			if jobOptions.Errors.Len() > 0 {
+				return fmt.Errorf("%v units failed", jobOptions.Errors.Len())
+			}
+			return nil
+		},
+	}
+}
+
+// Seeds each stage's last success from recorded scrape runs, so restarts do not rerun every stage. Runs with failed units do not count as successes
+func loadLastSuccesses(ctx context.Context, options *Options, s *scheduler.Scheduler) error {
+	database, err := options.OpenDatabase(ctx)
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	runs, err := database.ListScrapeRuns(ctx, 100)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for _, run := range runs {
+		if seen[run.Stage] || run.FinishedAt == nil || run.Error != "" || run.Counts["failures"] > 0 {
+			continue
+		}
+		seen[run.Stage] = true
+		s.SetLastSuccess(run.Stage, *run.FinishedAt)
+	}
+	return nil
+}
+
+func runServeScheduler(ctx context.Context, options *Options, args []string) error {
+	s := &scheduler.Scheduler{Jitter: options.Jitter}
+	for _, stage := range []string{"quarters", "subjects", "courses", "details"} {
+		if interval := options.Schedules[stage]; interval > 0 {
+			s.Jobs = append(s.Jobs, scheduledJob(options, stage, interval))
+		}
+	}
+	if len(s.Jobs) == 0 {
+		return errors.New("No stages scheduled")
+	}
+
+	if err := loadLastSuccesses(ctx, options, s); err != nil {
+		return err
+	}
+
+	server := &http.Server{Addr: options.Listen, Handler: s.Handler()}
+	go func() {
+		log.Printf("Serving status on %v\n", options.Listen)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Unable to serve status: %v\n", err)
+		}
+	}()
+
+	err := s.Run(ctx)
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	server.Shutdown(shutdownCtx)
+
+	// Being stopped is how the scheduler is meant to exit
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
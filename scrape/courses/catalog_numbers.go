@@ -1,48 +1,38 @@
-package main
+package courses
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
-	"net/http"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/brequin/brequin/scrape/soc"
 	"golang.org/x/net/html"
 )
 
-const subjectCoursesUrl = "https://sa.ucla.edu/ro/public/soc/Results"
-const courseTitlesViewUrl = "https://sa.ucla.edu/ro/public/soc/Results/CourseTitlesView"
+const subjectCoursesPath = "/ro/public/soc/Results"
+const courseTitlesViewPath = "/ro/public/soc/Results/CourseTitlesView"
 
-func ScrapePageCourseCatalogNumbers(quarterCode string, subjectAreaCode string, pageNumber int) ([]string, error) {
-	request, err := http.NewRequest("GET", courseTitlesViewUrl, nil)
-	if err != nil {
-		return nil, err
-	}
+// Lists every course of a subject area
+const noFilterFlags = "{}"
 
+func ScrapePageCourseCatalogNumbers(ctx context.Context, client *soc.Client, quarterCode string, subjectAreaCode string, filterFlags string, pageNumber int) ([]string, error) {
 	const modelTemplate = `{"term_cd":"%v","subj_area_cd":"%v"}`
 	model := fmt.Sprintf(modelTemplate, quarterCode, subjectAreaCode)
 
-	query := request.URL.Query()
+	query := url.Values{}
 	query.Add("search_by", "subject")
 	query.Add("model", model)
 	query.Add("pageNumber", strconv.Itoa(pageNumber))
-	query.Add("filterFlags", "{}")
-	request.URL.RawQuery = query.Encode()
-
-	// Required
-	request.Header.Add("X-Requested-With", "XMLHttpRequest")
+	query.Add("filterFlags", filterFlags)
 
-	response, err := http.DefaultClient.Do(request)
-	if err != nil {
-		return nil, err
-	}
-	defer response.Body.Close()
-
-	document, err := goquery.NewDocumentFromReader(response.Body)
+	document, err := client.FetchDocument(ctx, client.SocUrl(courseTitlesViewPath), query, true)
 	if err != nil {
 		return nil, err
 	}
@@ -70,25 +60,22 @@ func ScrapePageCourseCatalogNumbers(quarterCode string, subjectAreaCode string,
 	return catalogNumbers, nil
 }
 
-func ScrapeCourseCatalogNumbers(quarterCode string, subjectAreaCode string) ([]string, error) {
-	request, err := http.NewRequest("GET", subjectCoursesUrl, nil)
-	if err != nil {
-		return nil, err
-	}
+func ScrapeCourseCatalogNumbers(ctx context.Context, client *soc.Client, quarterCode string, subjectAreaCode string) ([]string, error) {
+	return ScrapeFilteredCourseCatalogNumbers(ctx, client, quarterCode, subjectAreaCode, noFilterFlags)
+}
 
-	query := request.URL.Query()
+// Lists the courses of a subject area matching the search filters in filterFlags
+func ScrapeFilteredCourseCatalogNumbers(ctx context.Context, client *soc.Client, quarterCode string, subjectAreaCode string, filterFlags string) ([]string, error) {
+	query := url.Values{}
 	query.Add("t", quarterCode)
 	query.Add("sBy", "subject")
 	query.Add("subj", subjectAreaCode)
-	request.URL.RawQuery = query.Encode()
-
-	response, err := http.DefaultClient.Do(request)
-	if err != nil {
-		return nil, err
+	// Left out when unfiltered so that cached responses stay valid
+	if filterFlags != noFilterFlags {
+		query.Add("filterFlags", filterFlags)
 	}
-	defer response.Body.Close()
 
-	document, err := goquery.NewDocumentFromReader(response.Body)
+	document, err := client.FetchDocument(ctx, client.SocUrl(subjectCoursesPath), query, false)
 	if err != nil {
 		return nil, err
 	}
@@ -108,6 +95,7 @@ func ScrapeCourseCatalogNumbers(quarterCode string, subjectAreaCode string) ([]s
 	}
 
 	var courseCatalogNumbers []string
+	var pageErr error
 	var coursesMutex sync.Mutex
 
 	var wg sync.WaitGroup
@@ -117,25 +105,41 @@ func ScrapeCourseCatalogNumbers(quarterCode string, subjectAreaCode string) ([]s
 		go func(p int) {
 			defer wg.Done()
 
-			pageCourseCatalogNumbers, err := ScrapePageCourseCatalogNumbers(quarterCode, subjectAreaCode, p)
+			pageCourseCatalogNumbers, err := ScrapePageCourseCatalogNumbers(ctx, client, quarterCode, subjectAreaCode, filterFlags, p)
+
+			coursesMutex.Lock()
+			defer coursesMutex.Unlock()
 			if err != nil {
-				log.Println(err)
+				pageErr = err
 				return
 			}
-
-			coursesMutex.Lock()
 			courseCatalogNumbers = append(courseCatalogNumbers, pageCourseCatalogNumbers...)
-			coursesMutex.Unlock()
 		}(pageNumber)
 	}
 	wg.Wait()
 
+	// A partial list would silently drop courses
+	if pageErr != nil {
+		return nil, pageErr
+	}
+
 	return courseCatalogNumbers, nil
 }
 
+// Prefix, digits and suffix, e.g. "CM", "121" and "A" of "CM121A"
+var catalogNumberPattern = regexp.MustCompile(`([[:upper:]]*)([[:digit:]]*)([[:upper:]]*)`)
+
+// M marks multiple-listed courses and C concurrently scheduled ones; "CM121" is both
+func CatalogNumberPrefix(catalogNumber string) string {
+	submatches := catalogNumberPattern.FindStringSubmatch(catalogNumber)
+	if submatches[2] == "" {
+		return ""
+	}
+	return submatches[1]
+}
+
 func FormatCatalogNumber(catalogNumber string) string {
-	re := regexp.MustCompile(`([[:upper:]]*)([[:digit:]]*)([[:upper:]]*)`)
-	submatches := re.FindStringSubmatch(catalogNumber)
+	submatches := catalogNumberPattern.FindStringSubmatch(catalogNumber)
 	prefix := submatches[1]
 	suffix := submatches[3]
 	number, err := strconv.Atoi(submatches[2])
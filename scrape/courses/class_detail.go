@@ -0,0 +1,233 @@
+package courses
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/soc"
+	"golang.org/x/net/html"
+)
+
+var finalExamHeadingPattern = regexp.MustCompile(`(?i)^final exam`)
+
+var restrictionsLabelPattern = regexp.MustCompile(`(?i)^(enrollment )?restrictions?\b`)
+var gradingLabelPattern = regexp.MustCompile(`(?i)^(grading|grade type)\b`)
+var feeLabelPattern = regexp.MustCompile(`(?i)^(course|class|material) fees?\b`)
+var notesLabelPattern = regexp.MustCompile(`(?i)^class notes?\b`)
+var impactedLabelPattern = regexp.MustCompile(`(?i)^impacted( class| course)?\b`)
+var dollarsPattern = regexp.MustCompile(`\$\s*([\d,]+)(?:\.(\d{2}))?`)
+
+var finalExamDateLayouts = []string{
+	"Monday, January 2, 2006",
+	"January 2, 2006",
+	"Mon, Jan 2, 2006",
+	"Jan 2, 2006",
+	"01/02/2006",
+	"1/2/2006",
+	time.DateOnly,
+}
+
+// Fetches the class detail page of each lecture in rows, adding what they list.
+// Details of the offering are read from the first lecture's page that loads.
+// Lectures whose pages fail to load are skipped, returning an error for each.
+func ScrapeClassDetails(ctx context.Context, client *soc.Client, offering db.QuarterCourse, rows *SectionRows) []error {
+	var errs []error
+	var hasOfferingDetails bool
+	for _, classDetail := range rows.classDetails {
+		document, err := client.FetchDocument(ctx, client.SocUrl(classDetail.path), nil, false)
+		if ctx.Err() != nil {
+			return errs
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Unable to get class details of %v: %w", classDetail.sectionId, err))
+			continue
+		}
+
+		if finalExam, ok := ParseFinalExam(document, classDetail.sectionId); ok {
+			rows.FinalExams = append(rows.FinalExams, finalExam)
+		}
+		if !hasOfferingDetails {
+			hasOfferingDetails = true
+			rows.OfferingDetails = append(rows.OfferingDetails, ParseOfferingDetails(document, offering))
+		}
+	}
+	return errs
+}
+
+// Finds the text following a label, such as "Class Notes", either after a colon in the label itself
+// or in the label's next sibling
+func labeledLines(document *goquery.Document, labelPattern *regexp.Regexp) ([]string, bool) {
+	label := document.Find("h1, h2, h3, h4, h5, h6, strong, b, th, dt, label, span, div, p, td").FilterFunction(func(i int, selection *goquery.Selection) bool {
+		return selection.Children().Length() == 0 && labelPattern.MatchString(strings.TrimSpace(selection.Text()))
+	}).First()
+	if label.Length() == 0 {
+		return nil, false
+	}
+
+	if _, value, found := strings.Cut(label.Text(), ":"); found && strings.TrimSpace(value) != "" {
+		return []string{strings.Join(strings.Fields(value), " ")}, true
+	}
+
+	// Text directly after the label, as in "<b>Grade Type:</b> Letter"
+	if sibling := label.Nodes[0].NextSibling; sibling != nil && sibling.Type == html.TextNode && strings.TrimSpace(sibling.Data) != "" {
+		return []string{strings.Join(strings.Fields(sibling.Data), " ")}, true
+	}
+
+	next := label.Next()
+	if next.Length() == 0 {
+		next = label.Parent().Next()
+	}
+	return columnLines(next), true
+}
+
+func parseGradingBasis(grading string) db.GradingBasis {
+	grading = strings.ToLower(grading)
+	letter := strings.Contains(grading, "letter")
+	passNoPass := strings.Contains(grading, "p/np") || strings.Contains(grading, "pass")
+	switch {
+	case strings.Contains(grading, "s/u") || strings.Contains(grading, "satisfactory"):
+		return db.GradingBasisSatisfactory
+	case letter && passNoPass:
+		return db.GradingBasisLetterOrPass
+	case letter:
+		return db.GradingBasisLetter
+	case passNoPass:
+		return db.GradingBasisPassNoPass
+	default:
+		return db.GradingBasisUnknown
+	}
+}
+
+func parseRestriction(description string) db.Restriction {
+	lower := strings.ToLower(description)
+	switch {
+	case strings.Contains(lower, "major"):
+		return db.Restriction{Kind: db.RestrictionKindMajor, Description: description}
+	case strings.Contains(lower, "standing") || strings.Contains(lower, "class level"):
+		return db.Restriction{Kind: db.RestrictionKindClassStanding, Description: description}
+	default:
+		return db.Restriction{Kind: db.RestrictionKindOther, Description: description}
+	}
+}
+
+// Sums every dollar amount listed, as separate fees may be listed
+func parseFeeCents(fees []string) int {
+	var cents int
+	for _, match := range dollarsPattern.FindAllStringSubmatch(strings.Join(fees, " "), -1) {
+		dollars, _ := strconv.Atoi(strings.ReplaceAll(match[1], ",", ""))
+		fraction, _ := strconv.Atoi(match[2])
+		cents += 100*dollars + fraction
+	}
+	return cents
+}
+
+// Reads restrictions, grading basis, fees, notes and impacted status from a class detail page.
+// Anything not listed is left empty.
+func ParseOfferingDetails(document *goquery.Document, offering db.QuarterCourse) db.OfferingDetails {
+	offeringDetails := db.OfferingDetails{QuarterCode: offering.QuarterCode, SubjectAreaCode: offering.SubjectAreaCode, CatalogNumber: offering.CatalogNumber}
+
+	if restrictions, ok := labeledLines(document, restrictionsLabelPattern); ok {
+		for _, restriction := range restrictions {
+			if strings.EqualFold(restriction, "none") {
+				continue
+			}
+			offeringDetails.Restrictions = append(offeringDetails.Restrictions, parseRestriction(restriction))
+		}
+	}
+	if grading, ok := labeledLines(document, gradingLabelPattern); ok {
+		offeringDetails.Grading = strings.Join(grading, " ")
+		offeringDetails.GradingBasis = parseGradingBasis(offeringDetails.Grading)
+	}
+	if fees, ok := labeledLines(document, feeLabelPattern); ok {
+		offeringDetails.FeeCents = parseFeeCents(fees)
+	}
+	if notes, ok := labeledLines(document, notesLabelPattern); ok {
+		offeringDetails.Notes = strings.Join(notes, "\n")
+	}
+	if impacted, ok := labeledLines(document, impactedLabelPattern); ok {
+		offeringDetails.Impacted = len(impacted) > 0 && strings.HasPrefix(strings.ToLower(impacted[0]), "y")
+	}
+
+	return offeringDetails
+}
+
+func parseFinalExamDate(text string) (string, bool) {
+	for _, layout := range finalExamDateLayouts {
+		if date, err := time.Parse(layout, text); err == nil {
+			return date.Format(time.DateOnly), true
+		}
+	}
+	return "", false
+}
+
+// Reads the final exam table of a class detail page. Columns are found by their headers,
+// falling back to date, day, time and location in that order.
+// Classes without a scheduled final list none, or list it as "None" or "TBA".
+func ParseFinalExam(document *goquery.Document, sectionId string) (db.FinalExam, bool) {
+	heading := document.Find("h1, h2, h3, h4, h5, h6, caption, strong, b, th, div, p").FilterFunction(func(i int, selection *goquery.Selection) bool {
+		return selection.Children().Length() == 0 && finalExamHeadingPattern.MatchString(strings.TrimSpace(selection.Text()))
+	}).First()
+	if heading.Length() == 0 {
+		return db.FinalExam{}, false
+	}
+
+	table := heading.Closest("table")
+	if table.Length() == 0 {
+		table = heading.NextAllFiltered("table").First()
+	}
+	for ancestor := heading.Parent(); table.Length() == 0 && ancestor.Length() > 0; ancestor = ancestor.Parent() {
+		table = ancestor.Find("table").First()
+	}
+	if table.Length() == 0 {
+		return db.FinalExam{}, false
+	}
+
+	columns := map[string]int{"date": 0, "day": 1, "time": 2, "location": 3}
+	table.Find("th").Each(func(i int, header *goquery.Selection) {
+		text := strings.ToLower(strings.TrimSpace(header.Text()))
+		for name := range columns {
+			if strings.Contains(text, name) {
+				columns[name] = i
+			}
+		}
+	})
+
+	var cells []string
+	table.Find("tr").EachWithBreak(func(i int, row *goquery.Selection) bool {
+		cells = nil
+		row.Find("td").Each(func(i int, cell *goquery.Selection) {
+			cells = append(cells, strings.Join(columnLines(cell), " "))
+		})
+		return len(cells) == 0
+	})
+	cell := func(name string) string {
+		if columns[name] < len(cells) {
+			return cells[columns[name]]
+		}
+		return ""
+	}
+
+	finalExam := db.FinalExam{SectionId: sectionId}
+	date, hasDate := parseFinalExamDate(cell("date"))
+	if !hasDate {
+		return db.FinalExam{}, false
+	}
+	finalExam.Date = date
+	if start, end, found := strings.Cut(cell("time"), "-"); found {
+		startTime, startOk := parseMeetingTime(start)
+		endTime, endOk := parseMeetingTime(end)
+		if startOk && endOk {
+			finalExam.StartTime, finalExam.EndTime = startTime, endTime
+		}
+	}
+	finalExam.Location = cell("location")
+	finalExam.Building, finalExam.Room = parseLocation(finalExam.Location)
+
+	return finalExam, true
+}
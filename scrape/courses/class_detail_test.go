@@ -0,0 +1,95 @@
+package courses
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/soc"
+)
+
+func TestParseFinalExam(t *testing.T) {
+	finalExam, ok := ParseFinalExam(readFixture(t, "class_detail.html"), "24F#COM SCI#31#Lec 1")
+	want := db.FinalExam{SectionId: "24F#COM SCI#31#Lec 1", Date: "2024-12-09", StartTime: "08:00", EndTime: "11:00", Location: "Boelter Hall 3400", Building: "Boelter Hall", Room: "3400"}
+	if !ok || finalExam != want {
+		t.Errorf("ParseFinalExam = %+v, %v; want %+v, true", finalExam, ok, want)
+	}
+
+	if finalExam, ok := ParseFinalExam(readFixture(t, "class_detail_no_final.html"), ""); ok {
+		t.Errorf("ParseFinalExam = %+v; want none for a class without a scheduled final", finalExam)
+	}
+}
+
+func TestScrapeClassDetailsSkipsFailedLectures(t *testing.T) {
+	doer := &fixtureDoer{t: t, pages: map[string]string{
+		"/detail/1": "",
+		"/detail/2": "class_detail.html",
+	}}
+	client := soc.NewClient()
+	client.SocBaseUrl = "https://soc.test"
+	client.Doer = doer
+
+	rows := SectionRows{classDetails: []classDetailLink{{sectionId: "Lec 1", path: "/detail/1"}, {sectionId: "Lec 2", path: "/detail/2"}}}
+	errs := ScrapeClassDetails(context.Background(), client, db.QuarterCourse{QuarterCode: "24F", SubjectAreaCode: "COM SCI", CatalogNumber: "31"}, &rows)
+
+	var statusError *soc.StatusError
+	if len(errs) != 1 || !errors.As(errs[0], &statusError) {
+		t.Errorf("Errors = %v; want the first lecture's only", errs)
+	}
+	if len(rows.FinalExams) != 1 || rows.FinalExams[0].SectionId != "Lec 2" {
+		t.Errorf("Final exams = %+v; want the second lecture's", rows.FinalExams)
+	}
+	// Offering details come from the first page that loads
+	if len(rows.OfferingDetails) != 1 || rows.OfferingDetails[0].Grading == "" {
+		t.Errorf("Offering details = %+v; want the second lecture's page's", rows.OfferingDetails)
+	}
+}
+
+func TestParseOfferingDetails(t *testing.T) {
+	offering := db.QuarterCourse{QuarterCode: "24F", SubjectAreaCode: "COM SCI", CatalogNumber: "31"}
+	offeringDetails := ParseOfferingDetails(readFixture(t, "class_detail.html"), offering)
+
+	want := db.OfferingDetails{
+		QuarterCode:     "24F",
+		SubjectAreaCode: "COM SCI",
+		CatalogNumber:   "31",
+		GradingBasis:    db.GradingBasisLetterOrPass,
+		Grading:         "Letter grade or Passed/Not Passed",
+		FeeCents:        2550,
+		Impacted:        true,
+		Notes:           "Students must attend the first discussion.\nLaptops required.",
+		Restrictions: []db.Restriction{
+			{Kind: db.RestrictionKindMajor, Description: "Major restricted to Computer Science"},
+			{Kind: db.RestrictionKindClassStanding, Description: "Class standing: juniors and seniors"},
+		},
+	}
+	if !reflect.DeepEqual(offeringDetails, want) {
+		t.Errorf("ParseOfferingDetails = %+v; want %+v", offeringDetails, want)
+	}
+
+	// Pages listing none of the details leave them empty
+	empty := ParseOfferingDetails(readFixture(t, "class_detail_no_final.html"), offering)
+	if want := (db.OfferingDetails{QuarterCode: "24F", SubjectAreaCode: "COM SCI", CatalogNumber: "31"}); !reflect.DeepEqual(empty, want) {
+		t.Errorf("ParseOfferingDetails = %+v; want %+v", empty, want)
+	}
+}
+
+func TestParseGradingBasis(t *testing.T) {
+	tests := map[string]db.GradingBasis{
+		"Letter grade":                      db.GradingBasisLetter,
+		"Passed/Not Passed":                 db.GradingBasisPassNoPass,
+		"P/NP":                              db.GradingBasisPassNoPass,
+		"Letter grade or Passed/Not Passed": db.GradingBasisLetterOrPass,
+		"S/U":                               db.GradingBasisSatisfactory,
+		"Satisfactory/Unsatisfactory":       db.GradingBasisSatisfactory,
+		"Other":                             db.GradingBasisUnknown,
+	}
+
+	for grading, want := range tests {
+		if got := parseGradingBasis(grading); got != want {
+			t.Errorf("parseGradingBasis(%q) = %q; want %q", grading, got, want)
+		}
+	}
+}
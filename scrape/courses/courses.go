@@ -0,0 +1,458 @@
+package courses
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/failures"
+	"github.com/brequin/brequin/scrape/filter"
+	"github.com/brequin/brequin/scrape/progress"
+	"github.com/brequin/brequin/scrape/report"
+	"github.com/brequin/brequin/scrape/soc"
+)
+
+const courseSummaryPath = "/ro/public/soc/Results/GetCourseSummary"
+
+// Path is required filler
+const modelTemplate = `{"Term":"%v","SubjectAreaCode":"%v","CatalogNumber":"%v","IsRoot":true,"Path":"0"}`
+
+var subjectAreaNameCodeMap map[string]string
+var subjectAreaIdCodeMap map[string]string
+
+// Everything scraped for one quarter and subject area
+type Scraped struct {
+	Nodes         []db.Node
+	Courses       []db.Course
+	Relations     []db.Relation
+	ContentHashes []db.ContentHash
+	Offerings     []db.QuarterCourse
+
+	GeCategories          []db.CourseGeCategory
+	RequisiteObservations []db.RequisiteObservation
+
+	SectionRows
+
+	mutex sync.Mutex
+}
+
+func (s *Scraped) add(fn func(s *Scraped)) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	fn(s)
+}
+
+// Writes in dependency order; meant to be called in a transaction
+func (s *Scraped) Write(ctx context.Context, tx db.Store) error {
+	if err := tx.InsertQuarterCourses(ctx, s.Offerings); err != nil {
+		return err
+	}
+	if err := tx.InsertCourseGeCategories(ctx, s.GeCategories); err != nil {
+		return err
+	}
+	if err := tx.InsertNodes(ctx, s.Nodes); err != nil {
+		return err
+	}
+	if err := tx.InsertCourses(ctx, s.Courses); err != nil {
+		return err
+	}
+	if err := tx.InsertRelations(ctx, s.Relations); err != nil {
+		return err
+	}
+	if err := tx.InsertRequisiteObservations(ctx, s.RequisiteObservations); err != nil {
+		return err
+	}
+	if err := tx.InsertSections(ctx, s.Sections); err != nil {
+		return err
+	}
+	if err := tx.InsertMeetings(ctx, s.Sections, s.Meetings); err != nil {
+		return err
+	}
+	if err := tx.InsertEnrollmentSnapshots(ctx, s.EnrollmentSnapshots); err != nil {
+		return err
+	}
+	if err := tx.InsertInstructors(ctx, s.Instructors); err != nil {
+		return err
+	}
+	if err := tx.InsertSectionInstructors(ctx, s.SectionInstructors); err != nil {
+		return err
+	}
+	if err := tx.InsertFinalExams(ctx, s.FinalExams); err != nil {
+		return err
+	}
+	if err := tx.InsertOfferingDetails(ctx, s.OfferingDetails); err != nil {
+		return err
+	}
+	return tx.UpsertContentHashes(ctx, s.ContentHashes)
+}
+
+// Failures of individual courses are added to errs rather than returned, and parse failures are saved to snapshots.
+// Requisites of courses whose requisite expression hash matches previousHashes are skipped when incremental;
+// the hashes of all other courses are returned. Sections and class details, which the hash does not cover, are always scraped.
+// GE categories are searched unless incremental, when new courses are listed, or when refreshGe.
+func ScrapeQuarterSubjectArea(ctx context.Context, client *soc.Client, errs *report.Collector, snapshots failures.Snapshots, quarter db.Quarter, subjectArea db.SubjectArea, previousHashes map[string]string, incremental bool, refreshGe bool) (*Scraped, error) {
+	catalogNumbers, err := ScrapeCourseCatalogNumbers(ctx, client, quarter.Code, subjectArea.Code)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		log.Println("Unable to determine course catalog numbers")
+		return nil, err
+	}
+
+	addError := func(catalogNumber string, kind report.Kind, err error) {
+		errs.Add(&report.Error{Stage: "courses", Kind: kind, QuarterCode: quarter.Code, SubjectAreaCode: subjectArea.Code, CatalogNumber: catalogNumber, Err: err})
+	}
+
+	saveSnapshot := func(catalogNumber string, url string, page []byte, requisiteExpression RequisiteExpression, err error) {
+		if !snapshots.Enabled() {
+			return
+		}
+
+		snapshot := failures.Snapshot{
+			QuarterCode:     quarter.Code,
+			SubjectAreaCode: subjectArea.Code,
+			CatalogNumber:   catalogNumber,
+			Url:             url,
+			Error:           err.Error(),
+			Page:            page,
+			Expression:      requisiteExpression.String(),
+		}
+		if requisiteExpression.String() != "" {
+			snapshot.Tokens = FormatTokens(*requisiteExpression.Tokenize())
+		}
+		if err := snapshots.Save(snapshot); err != nil {
+			log.Printf("Unable to save failure snapshot for %v %v: %v\n", subjectArea.Code, catalogNumber, err)
+		}
+	}
+
+	// Every listed course is offered, even if its summary fails to scrape
+	scraped := &Scraped{}
+	for _, catalogNumber := range catalogNumbers {
+		scraped.Offerings = append(scraped.Offerings, db.QuarterCourse{QuarterCode: quarter.Code, SubjectAreaCode: subjectArea.Code, CatalogNumber: catalogNumber})
+	}
+
+	var wg sync.WaitGroup
+
+	// Only courses listed without filters are offered
+	offered := make(map[string]bool)
+	for _, catalogNumber := range catalogNumbers {
+		offered[catalogNumber] = true
+	}
+	// Each GE category is a paginated search of its own, so incremental runs only search when new courses are listed
+	// or when asked to refresh GE membership
+	searchGeCategories := !incremental || refreshGe
+	for _, catalogNumber := range catalogNumbers {
+		if _, exists := previousHashes[catalogNumber]; !exists {
+			searchGeCategories = true
+		}
+	}
+	for _, geCategory := range GeCategories {
+		if !searchGeCategories {
+			break
+		}
+
+		wg.Add(1)
+
+		go func(c GeCategory) {
+			defer wg.Done()
+
+			courseGeCategories, err := ScrapeGeCategoryCourses(ctx, client, quarter.Code, subjectArea.Code, c)
+			if ctx.Err() != nil {
+				return
+			}
+			// Fails the unit so that it is retried, though its courses are still written
+			if err != nil {
+				log.Printf("Unable to determine courses in GE category %v %v\n", c.Foundation, c.Category)
+				addError("", report.Classify(err), fmt.Errorf("GE category %v/%v: %w", c.Foundation, c.Category, err))
+				return
+			}
+
+			scraped.add(func(s *Scraped) {
+				for _, courseGeCategory := range courseGeCategories {
+					if offered[courseGeCategory.CatalogNumber] {
+						s.GeCategories = append(s.GeCategories, courseGeCategory)
+					}
+				}
+			})
+		}(geCategory)
+	}
+
+	for _, catalogNumber := range catalogNumbers {
+		wg.Add(1)
+
+		go func(n string) {
+			defer wg.Done()
+
+			formattedCatalogNumber := FormatCatalogNumber(n)
+			model := fmt.Sprintf(modelTemplate, quarter.Code, subjectArea.Code, formattedCatalogNumber)
+
+			query := url.Values{}
+			query.Add("model", model)
+			query.Add("filterFlags", "{}")
+
+			document, err := client.FetchDocument(ctx, client.SocUrl(courseSummaryPath), query, false)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Println("Unable to get course summary")
+				addError(n, report.Classify(err), err)
+				return
+			}
+
+			classInfoDiv := document.Find("div.class-not-checked.class-info").First()
+
+			fakeClassId, exists := classInfoDiv.Attr("id")
+			if !exists {
+				log.Printf("Unable to determine fake class id for class: %v %v\n", subjectArea.Code, n)
+				addError(n, report.KindParse, errors.New("Unable to determine fake class id"))
+				return
+			}
+
+			nodeId := db.ValueNodeId(subjectArea.Code, n)
+			course := db.Course{SubjectAreaCode: subjectArea.Code, CatalogNumber: n, NodeId: nodeId}
+			sectionRows := ParseSections(document, time.Now(), quarter.Code, subjectArea.Code, n)
+			offering := db.QuarterCourse{QuarterCode: quarter.Code, SubjectAreaCode: subjectArea.Code, CatalogNumber: n}
+			scrapeClassDetails := func() {
+				for _, err := range ScrapeClassDetails(ctx, client, offering, &sectionRows) {
+					log.Println(err)
+					addError(n, report.Classify(err), err)
+				}
+			}
+			addCourse := func() {
+				scraped.add(func(s *Scraped) {
+					s.Nodes = append(s.Nodes, db.Node{Id: nodeId, Type: db.NodeTypeValue})
+					s.Courses = append(s.Courses, course)
+					s.SectionRows.append(sectionRows)
+				})
+			}
+
+			// Courses without a class detail link are kept, but their requisites are unknown rather than empty
+			classDetailPath, exists := classInfoDiv.Find("div#" + fakeClassId + "-section").Find("a").Attr("href")
+			if !exists {
+				log.Println("Unable to determine class detail path")
+				scrapeClassDetails()
+				addCourse()
+				addError(n, report.KindParse, errors.New("Unable to determine class detail path"))
+				return
+			}
+
+			classDetailTooltipUrl := strings.Replace(client.SocUrl(classDetailPath), "ClassDetail", "ClassDetailTooltip", 1)
+			requisiteExpression, page, err := ScrapeRequisiteExpression(ctx, client, classDetailTooltipUrl)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Println("Unable to determine requisite expression from class detail tooltip")
+				scrapeClassDetails()
+				addCourse()
+				addError(n, report.Classify(err), err)
+				// Only pages that were fetched failed to parse
+				if page != nil {
+					saveSnapshot(n, classDetailTooltipUrl, page, requisiteExpression, err)
+				}
+				return
+			}
+
+			// Final exams and offering details can change while requisites stay the same
+			scrapeClassDetails()
+			addCourse()
+
+			hash := requisiteExpression.Hash()
+			if incremental && previousHashes[n] == hash {
+				return
+			}
+
+			tooltipNodes, tooltipCourses, tooltipRelations, err := requisiteExpression.EvaluateForCourse(course)
+			if err != nil {
+				log.Println("Unable to parse requisite expression: " + requisiteExpression.string)
+				addError(n, report.KindParse, err)
+				saveSnapshot(n, classDetailTooltipUrl, page, requisiteExpression, err)
+				return
+			}
+
+			scraped.add(func(s *Scraped) {
+				s.Nodes = append(s.Nodes, tooltipNodes...)
+				s.Courses = append(s.Courses, tooltipCourses...)
+				s.Relations = append(s.Relations, tooltipRelations...)
+				s.RequisiteObservations = append(s.RequisiteObservations, db.RequisiteObservation{CourseNodeId: nodeId, QuarterCode: quarter.Code, Relations: tooltipRelations})
+				s.ContentHashes = append(s.ContentHashes, db.ContentHash{QuarterCode: quarter.Code, SubjectAreaCode: subjectArea.Code, CatalogNumber: n, Hash: hash})
+			})
+		}(catalogNumber)
+	}
+	wg.Wait()
+
+	// A cancelled unit is incomplete and must not be written
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return scraped, nil
+}
+
+type Input struct {
+	Quarters            []db.Quarter
+	SubjectAreas        []db.SubjectArea
+	QuarterSubjectAreas map[string][]db.SubjectArea // Keyed by quarter code
+}
+
+type Counts struct {
+	Nodes               int
+	Courses             int
+	Relations           int
+	Offerings           int
+	GeCategories        int
+	Sections            int
+	Meetings            int
+	EnrollmentSnapshots int
+	FinalExams          int
+	Changed             int      // Courses whose requisite expression is new or differs from the stored hash
+	ChangedUnits        []string // The changed courses, e.g. "24F COM SCI 31"
+}
+
+func (c Counts) Map() map[string]int {
+	return map[string]int{"nodes": c.Nodes, "courses": c.Courses, "relations": c.Relations, "quarter_courses": c.Offerings, "course_ge_categories": c.GeCategories, "sections": c.Sections, "meetings": c.Meetings, "enrollment_snapshots": c.EnrollmentSnapshots, "final_exams": c.FinalExams, "changed": c.Changed}
+}
+
+// Quarter subject areas are only loaded for the quarters f matches
+func LoadInput(ctx context.Context, database db.Store, f *filter.Filter) (Input, error) {
+	quarters, err := database.ListQuarters(ctx)
+	if err != nil {
+		return Input{}, err
+	}
+
+	subjectAreas, err := database.ListSubjectAreas(ctx)
+	if err != nil {
+		return Input{}, err
+	}
+
+	quarterSubjectAreas := make(map[string][]db.SubjectArea)
+	for _, quarter := range f.Quarters(quarters) {
+		subjectAreas, err := database.ListQuarterSubjectAreas(ctx, quarter)
+		if err != nil {
+			return Input{}, err
+		}
+		quarterSubjectAreas[quarter.Code] = subjectAreas
+	}
+
+	return Input{Quarters: quarters, SubjectAreas: subjectAreas, QuarterSubjectAreas: quarterSubjectAreas}, nil
+}
+
+// Requisites naming other subject areas are only recognized as courses once those subject areas are set
+func SetSubjectAreas(subjectAreas []db.SubjectArea) {
+	subjectAreaNameCodeMap = make(map[string]string)
+	subjectAreaIdCodeMap = make(map[string]string)
+	for _, subjectArea := range subjectAreas {
+		subjectAreaNameCodeMap[subjectArea.Name] = subjectArea.Code
+		subjectAreaIdCodeMap[strings.ReplaceAll(subjectArea.Code, " ", "")] = subjectArea.Code
+	}
+}
+
+// Incremental runs skip the requisites of courses whose requisite expression is unchanged,
+// and, unless refreshGe, the GE searches of subject areas listing no new courses
+func Run(ctx context.Context, client *soc.Client, database db.Store, input Input, f *filter.Filter, tracker *progress.Tracker, errs *report.Collector, snapshots failures.Snapshots, incremental bool, refreshGe bool) (Counts, error) {
+	SetSubjectAreas(input.SubjectAreas)
+
+	var counts Counts
+	var countsMutex sync.Mutex
+
+	for _, quarter := range f.Quarters(input.Quarters) {
+		var wg sync.WaitGroup
+		for _, subjectArea := range f.SubjectAreas(input.QuarterSubjectAreas[quarter.Code]) {
+			if ctx.Err() != nil {
+				break
+			}
+			if tracker.Skip(quarter.Code, subjectArea.Code) {
+				continue
+			}
+
+			wg.Add(1)
+
+			go func(s db.SubjectArea) {
+				defer wg.Done()
+
+				previousHashes := make(map[string]string)
+				contentHashes, err := database.ListContentHashes(ctx, quarter.Code, s.Code)
+				if err != nil {
+					errs.Add(&report.Error{Stage: "courses", Kind: report.KindDatabase, QuarterCode: quarter.Code, SubjectAreaCode: s.Code, Err: err})
+					tracker.Fail(ctx, quarter.Code, s.Code, err)
+					return
+				}
+				for _, contentHash := range contentHashes {
+					previousHashes[contentHash.CatalogNumber] = contentHash.Hash
+				}
+
+				scraped, err := ScrapeQuarterSubjectArea(ctx, client, errs, snapshots, quarter, s, previousHashes, incremental, refreshGe)
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil {
+					errs.Add(&report.Error{Stage: "courses", Kind: report.Classify(err), QuarterCode: quarter.Code, SubjectAreaCode: s.Code, Err: err})
+					tracker.Fail(ctx, quarter.Code, s.Code, err)
+					return
+				}
+
+				msg := fmt.Sprintf("%v %v: Scraped %v nodes, %v courses, %v relations, %v sections of %v offered courses", quarter.Code, s.Code, len(scraped.Nodes), len(scraped.Courses), len(scraped.Relations), len(scraped.Sections), len(scraped.Offerings))
+				log.Println(msg)
+
+				var changed []string
+				for _, contentHash := range scraped.ContentHashes {
+					if previousHashes[contentHash.CatalogNumber] != contentHash.Hash {
+						changed = append(changed, contentHash.CatalogNumber)
+					}
+				}
+				if len(changed) > 0 {
+					sort.Strings(changed)
+					log.Printf("%v %v: Requisites changed for %v\n", quarter.Code, s.Code, strings.Join(changed, ", "))
+				}
+
+				err = database.InTx(ctx, func(tx db.Store) error {
+					return scraped.Write(ctx, tx)
+				})
+				if ctx.Err() != nil {
+					return
+				}
+				if err != nil {
+					errs.Add(&report.Error{Stage: "courses", Kind: report.KindDatabase, QuarterCode: quarter.Code, SubjectAreaCode: s.Code, Err: err})
+					tracker.Fail(ctx, quarter.Code, s.Code, err)
+					return
+				}
+
+				if failedCount := errs.CountUnit("courses", quarter.Code, s.Code); failedCount > 0 {
+					tracker.Fail(ctx, quarter.Code, s.Code, fmt.Errorf("%v courses or GE searches failed", failedCount))
+				} else {
+					tracker.Complete(ctx, quarter.Code, s.Code)
+				}
+
+				countsMutex.Lock()
+				counts.Nodes += len(scraped.Nodes)
+				counts.Courses += len(scraped.Courses)
+				counts.Relations += len(scraped.Relations)
+				counts.Offerings += len(scraped.Offerings)
+				counts.GeCategories += len(scraped.GeCategories)
+				counts.Sections += len(scraped.Sections)
+				counts.Meetings += len(scraped.Meetings)
+				counts.EnrollmentSnapshots += len(scraped.EnrollmentSnapshots)
+				counts.FinalExams += len(scraped.FinalExams)
+				counts.Changed += len(changed)
+				for _, catalogNumber := range changed {
+					counts.ChangedUnits = append(counts.ChangedUnits, fmt.Sprintf("%v %v %v", quarter.Code, s.Code, catalogNumber))
+				}
+				countsMutex.Unlock()
+			}(subjectArea)
+		}
+		wg.Wait()
+	}
+
+	sort.Strings(counts.ChangedUnits)
+	return counts, ctx.Err()
+}
@@ -0,0 +1,121 @@
+package courses
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/failures"
+	"github.com/brequin/brequin/scrape/report"
+	"github.com/brequin/brequin/scrape/soc"
+)
+
+// Fails GE searches, which request the subject area's results page with filterFlags, and serves everything else
+type geFailingDoer struct {
+	*fixtureDoer
+}
+
+func (d geFailingDoer) Do(request *http.Request) (*http.Response, error) {
+	if request.URL.Path == subjectCoursesPath && request.URL.Query().Get("filterFlags") != "" {
+		return &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}, Body: http.NoBody, Request: request}, nil
+	}
+	return d.fixtureDoer.Do(request)
+}
+
+type comSciScrape struct {
+	classDetail    string
+	previousHashes map[string]string
+	refreshGe      bool
+	failGe         bool
+}
+
+func (c comSciScrape) run(t *testing.T) (*Scraped, *report.Collector) {
+	t.Helper()
+
+	doer := &fixtureDoer{t: t, pages: map[string]string{
+		subjectCoursesPath:                          "subject_courses.html",
+		courseTitlesViewPath + "#1":                 "course_titles.html",
+		courseSummaryPath:                           "course_summary.html",
+		"/ro/public/soc/Results/ClassDetailTooltip": "class_detail_tooltip.html",
+		"/ro/public/soc/Results/ClassDetail":        c.classDetail,
+	}}
+	client := soc.NewClient()
+	client.SocBaseUrl = "https://soc.test"
+	client.Doer = doer
+	// Every GE category is searched, which the default rate drags out
+	client.Limiter.RequestsPerSecond = 1000
+	if c.failGe {
+		client.Doer = geFailingDoer{doer}
+	}
+
+	ctx, errs := report.NewCollector(context.Background(), report.PolicyBestEffort)
+	scraped, err := ScrapeQuarterSubjectArea(ctx, client, errs, failures.Snapshots{}, db.Quarter{Code: "24F"}, db.SubjectArea{Code: "COM SCI"}, c.previousHashes, true, c.refreshGe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return scraped, errs
+}
+
+func scrapeComSci(t *testing.T, classDetail string, previousHashes map[string]string) *Scraped {
+	t.Helper()
+
+	scraped, errs := comSciScrape{classDetail: classDetail, previousHashes: previousHashes}.run(t)
+	if errs.Len() > 0 {
+		t.Fatalf("Errors = %v", errs.Errors())
+	}
+	return scraped
+}
+
+func TestScrapeQuarterSubjectAreaClassDetailsChanged(t *testing.T) {
+	// A stale hash makes the first run scrape requisites without searching GE categories
+	first := scrapeComSci(t, "class_detail_no_final.html", map[string]string{"31": "stale"})
+	if len(first.FinalExams) != 0 || len(first.ContentHashes) != 1 {
+		t.Fatalf("First run final exams = %+v, content hashes = %+v; want none and one", first.FinalExams, first.ContentHashes)
+	}
+
+	// Only the class detail page changed, so requisites are skipped but the final exam is still found
+	second := scrapeComSci(t, "class_detail.html", map[string]string{"31": first.ContentHashes[0].Hash})
+	if len(second.Relations) != 0 || len(second.ContentHashes) != 0 {
+		t.Errorf("Second run relations = %+v, content hashes = %+v; want requisites skipped", second.Relations, second.ContentHashes)
+	}
+	if len(second.FinalExams) != 1 || second.FinalExams[0].Date != "2024-12-09" {
+		t.Errorf("Second run final exams = %+v; want the changed class detail page's", second.FinalExams)
+	}
+	if len(second.OfferingDetails) != 1 {
+		t.Errorf("Second run offering details = %+v; want one", second.OfferingDetails)
+	}
+}
+
+func TestScrapeQuarterSubjectAreaRefreshGe(t *testing.T) {
+	first := scrapeComSci(t, "class_detail.html", map[string]string{"31": "stale"})
+	previousHashes := map[string]string{"31": first.ContentHashes[0].Hash}
+
+	if unchanged := scrapeComSci(t, "class_detail.html", previousHashes); len(unchanged.GeCategories) != 0 {
+		t.Errorf("GE categories without refreshGe = %+v; want none searched", unchanged.GeCategories)
+	}
+
+	refreshed, errs := comSciScrape{classDetail: "class_detail.html", previousHashes: previousHashes, refreshGe: true}.run(t)
+	if errs.Len() > 0 {
+		t.Fatalf("Errors = %v", errs.Errors())
+	}
+	// The stand-in search results list the course under every category
+	if len(refreshed.GeCategories) != len(GeCategories) {
+		t.Errorf("GE categories with refreshGe = %v; want %v", len(refreshed.GeCategories), len(GeCategories))
+	}
+}
+
+func TestScrapeQuarterSubjectAreaGeFailureFailsUnit(t *testing.T) {
+	scraped, errs := comSciScrape{classDetail: "class_detail.html", previousHashes: map[string]string{"31": "stale"}, refreshGe: true, failGe: true}.run(t)
+
+	if count := errs.CountUnit("courses", "24F", "COM SCI"); count != len(GeCategories) {
+		t.Errorf("CountUnit(courses) = %v; want one failure per GE category, %v", count, len(GeCategories))
+	}
+	if count := errs.CountStage("courses"); count != len(GeCategories) {
+		t.Errorf("CountStage(courses) = %v; want %v", count, len(GeCategories))
+	}
+	// The courses themselves are still scraped
+	if len(scraped.Courses) == 0 || len(scraped.FinalExams) != 1 {
+		t.Errorf("Courses = %+v, final exams = %+v; want the course scraped", scraped.Courses, scraped.FinalExams)
+	}
+}
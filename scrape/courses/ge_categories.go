@@ -0,0 +1,56 @@
+package courses
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/soc"
+)
+
+// A General Education category within one of the three foundations
+type GeCategory struct {
+	Foundation string
+	Category   string
+	Name       string
+}
+
+// Keys of the schedule of classes' GE search filter
+const geFilterFlagsTemplate = `{"ge_cat_cd":"%v","ge_sub_cat_cd":"%v"}`
+
+var GeFoundationNames = map[string]string{
+	"AH": "Foundations of the Arts and Humanities",
+	"SC": "Foundations of Society and Culture",
+	"SI": "Foundations of Scientific Inquiry",
+}
+
+var GeCategories = []GeCategory{
+	{Foundation: "AH", Category: "LC", Name: "Literary and Cultural Analysis"},
+	{Foundation: "AH", Category: "PL", Name: "Philosophical and Linguistic Analysis"},
+	{Foundation: "AH", Category: "VP", Name: "Visual and Performance Arts Analysis and Practice"},
+	{Foundation: "SC", Category: "HA", Name: "Historical Analysis"},
+	{Foundation: "SC", Category: "SA", Name: "Social Analysis"},
+	{Foundation: "SI", Category: "LS", Name: "Life Sciences"},
+	{Foundation: "SI", Category: "PS", Name: "Physical Sciences"},
+}
+
+// Lists the courses of a subject area counting towards a GE category in a quarter
+func ScrapeGeCategoryCourses(ctx context.Context, client *soc.Client, quarterCode, subjectAreaCode string, geCategory GeCategory) ([]db.CourseGeCategory, error) {
+	filterFlags := fmt.Sprintf(geFilterFlagsTemplate, geCategory.Foundation, geCategory.Category)
+	catalogNumbers, err := ScrapeFilteredCourseCatalogNumbers(ctx, client, quarterCode, subjectAreaCode, filterFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	var courseGeCategories []db.CourseGeCategory
+	for _, catalogNumber := range catalogNumbers {
+		courseGeCategories = append(courseGeCategories, db.CourseGeCategory{
+			QuarterCode:     quarterCode,
+			SubjectAreaCode: subjectAreaCode,
+			CatalogNumber:   catalogNumber,
+			Foundation:      geCategory.Foundation,
+			Category:        geCategory.Category,
+		})
+	}
+	return courseGeCategories, nil
+}
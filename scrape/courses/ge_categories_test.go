@@ -0,0 +1,91 @@
+package courses
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/brequin/brequin/scrape/soc"
+)
+
+// Serves testdata pages by request path and page number, recording each request's filterFlags.
+// Paths mapped to no page are not found.
+type fixtureDoer struct {
+	t     *testing.T
+	pages map[string]string
+
+	mutex       sync.Mutex
+	filterFlags []string
+}
+
+func (d *fixtureDoer) Do(request *http.Request) (*http.Response, error) {
+	d.mutex.Lock()
+	d.filterFlags = append(d.filterFlags, request.URL.Query().Get("filterFlags"))
+	d.mutex.Unlock()
+
+	key := request.URL.Path
+	if pageNumber := request.URL.Query().Get("pageNumber"); pageNumber != "" {
+		key += "#" + pageNumber
+	}
+	name, exists := d.pages[key]
+	if !exists {
+		d.t.Errorf("Unexpected request for %v", request.URL)
+	}
+	if name == "" {
+		return &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}, Body: http.NoBody, Request: request}, nil
+	}
+	page, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(page)), Request: request}, nil
+}
+
+func TestScrapeGeCategoryCourses(t *testing.T) {
+	doer := &fixtureDoer{t: t, pages: map[string]string{
+		subjectCoursesPath:          "ge_results.html",
+		courseTitlesViewPath + "#1": "ge_course_titles_1.html",
+		courseTitlesViewPath + "#2": "ge_course_titles_2.html",
+	}}
+	client := soc.NewClient()
+	client.SocBaseUrl = "https://soc.test"
+	client.Doer = doer
+
+	courseGeCategories, err := ScrapeGeCategoryCourses(context.Background(), client, "24F", "COM SCI", GeCategory{Foundation: "SI", Category: "PS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var catalogNumbers []string
+	for _, c := range courseGeCategories {
+		if c.QuarterCode != "24F" || c.SubjectAreaCode != "COM SCI" || c.Foundation != "SI" || c.Category != "PS" {
+			t.Errorf("Unexpected GE category %+v", c)
+		}
+		catalogNumbers = append(catalogNumbers, c.CatalogNumber)
+	}
+	sort.Strings(catalogNumbers)
+	if want := []string{"1", "CM121", "M51A"}; !slices.Equal(catalogNumbers, want) {
+		t.Errorf("Catalog numbers = %v; want %v", catalogNumbers, want)
+	}
+
+	if len(doer.filterFlags) != 3 {
+		t.Fatalf("Sent %v requests; want 3", len(doer.filterFlags))
+	}
+	for _, filterFlags := range doer.filterFlags {
+		var keys map[string]string
+		if err := json.Unmarshal([]byte(filterFlags), &keys); err != nil {
+			t.Fatalf("filterFlags %q is not JSON: %v", filterFlags, err)
+		}
+		if len(keys) != 2 || keys["ge_cat_cd"] != "SI" || keys["ge_sub_cat_cd"] != "PS" {
+			t.Errorf("filterFlags = %v; want the foundation under ge_cat_cd and the category under ge_sub_cat_cd", filterFlags)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package courses
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/brequin/brequin/scrape/db"
+)
+
+// Listed in place of instructors, not instructors themselves
+var instructorPlaceholders = map[string]bool{
+	"staff":     true,
+	"the staff": true,
+	"ta":        true,
+	"tba":       true,
+}
+
+// Reads a name as listed, e.g. "Smith, J." or "Smith, John". Names with the same last name and first initial
+// are taken to be the same instructor, so abbreviated and full listings merge.
+func ParseInstructor(name string) (db.Instructor, bool) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" || instructorPlaceholders[strings.ToLower(name)] {
+		return db.Instructor{}, false
+	}
+
+	lastName, firstNames, _ := strings.Cut(name, ",")
+	var firstInitial string
+	for _, char := range firstNames {
+		if unicode.IsLetter(char) {
+			firstInitial = string(char)
+			break
+		}
+	}
+
+	return db.Instructor{Id: db.InstructorId(strings.TrimSpace(lastName), firstInitial), Name: name}, true
+}
@@ -0,0 +1,28 @@
+package courses
+
+import "testing"
+
+func TestParseInstructor(t *testing.T) {
+	tests := []struct {
+		name string
+		id   string
+		ok   bool
+	}{
+		{"Smith, J.", "smith#j", true},
+		{"Smith, John", "smith#j", true},
+		{"SMITH,  John A.", "smith#j", true},
+		{"Smith, Jane", "smith#j", true},
+		{"De La Cruz, Mary-Jane", "de la cruz#m", true},
+		{"Sharma", "sharma#", true},
+		{"Staff", "", false},
+		{"TBA", "", false},
+		{"  ", "", false},
+	}
+
+	for _, test := range tests {
+		instructor, ok := ParseInstructor(test.name)
+		if ok != test.ok || instructor.Id != test.id {
+			t.Errorf("ParseInstructor(%q) = %q, %v; want %q, %v", test.name, instructor.Id, ok, test.id, test.ok)
+		}
+	}
+}
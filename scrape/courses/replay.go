@@ -0,0 +1,33 @@
+package courses
+
+import (
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/failures"
+)
+
+// Parses a snapshot's saved page again with the current parser; the returned snapshot holds the new artifacts
+func Replay(snapshot failures.Snapshot) (failures.Snapshot, error) {
+	replayed := snapshot
+	replayed.Expression = ""
+	replayed.Tokens = nil
+	replayed.Error = ""
+
+	requisiteExpression, err := ParseRequisiteExpression(snapshot.Page)
+	if err != nil {
+		replayed.Error = err.Error()
+		return replayed, err
+	}
+	replayed.Expression = requisiteExpression.String()
+	if replayed.Expression != "" {
+		replayed.Tokens = FormatTokens(*requisiteExpression.Tokenize())
+	}
+
+	nodeId := db.ValueNodeId(snapshot.SubjectAreaCode, snapshot.CatalogNumber)
+	course := db.Course{SubjectAreaCode: snapshot.SubjectAreaCode, CatalogNumber: snapshot.CatalogNumber, NodeId: nodeId}
+	if _, _, _, err := requisiteExpression.EvaluateForCourse(course); err != nil {
+		replayed.Error = err.Error()
+		return replayed, err
+	}
+
+	return replayed, nil
+}
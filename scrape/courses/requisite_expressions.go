@@ -1,14 +1,18 @@
-package main
+package courses
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"log"
-	"net/http"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/soc"
 	"golang.org/x/net/html"
 )
 
@@ -35,6 +39,24 @@ const (
 	TokenEnd
 )
 
+func (t TokenType) String() string {
+	switch t {
+	case TokenRequisite:
+		return "requisite"
+	case TokenLParen:
+		return "lparen"
+	case TokenRParen:
+		return "rparen"
+	case TokenAnd:
+		return "and"
+	case TokenOr:
+		return "or"
+	case TokenEnd:
+		return "end"
+	}
+	return fmt.Sprintf("TokenType(%d)", int(t))
+}
+
 type Token struct {
 	Type  TokenType
 	Value string
@@ -304,13 +326,38 @@ func Factors(tokens *[]Token) (id string, factors []ParseNode, nodes []db.Node,
 	return id, factors, nodes, courses, relations, nil
 }
 
-func (requisiteExpression RequisiteExpression) EvaluateForCourse(course db.Course) ([]db.Node, []db.Course, []db.Relation, error) {
+func (requisiteExpression RequisiteExpression) Hash() string {
+	sum := sha256.Sum256([]byte(requisiteExpression.string))
+	return hex.EncodeToString(sum[:])
+}
+
+func (requisiteExpression RequisiteExpression) String() string {
+	return requisiteExpression.string
+}
+
+// One "type<TAB>value" line per token, as saved in failure snapshots
+func FormatTokens(tokens []Token) []string {
+	var lines []string
+	for _, token := range tokens {
+		lines = append(lines, fmt.Sprintf("%v\t%v", token.Type, token.Value))
+	}
+	return lines
+}
+
+// Malformed expressions are returned as errors, even those the parser would panic on
+func (requisiteExpression RequisiteExpression) EvaluateForCourse(course db.Course) (nodes []db.Node, courses []db.Course, relations []db.Relation, err error) {
 	if len(requisiteExpression.string) == 0 {
 		return nil, nil, nil, nil
 	}
 
+	defer func() {
+		if r := recover(); r != nil {
+			nodes, courses, relations, err = nil, nil, nil, fmt.Errorf("Malformed requisite expression: %v", r)
+		}
+	}()
+
 	tokens := requisiteExpression.Tokenize()
-	nodes, courses, relations, err := Start(course, tokens)
+	nodes, courses, relations, err = Start(course, tokens)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -318,22 +365,26 @@ func (requisiteExpression RequisiteExpression) EvaluateForCourse(course db.Cours
 	return nodes, courses, relations, nil
 }
 
-func ScrapeRequisiteExpression(classDetailTooltipUrl string) (RequisiteExpression, error) {
-	request, err := http.NewRequest("GET", classDetailTooltipUrl, nil)
+// The fetched page is returned even if it cannot be parsed
+func ScrapeRequisiteExpression(ctx context.Context, client *soc.Client, classDetailTooltipUrl string) (RequisiteExpression, []byte, error) {
+	page, err := client.Fetch(ctx, classDetailTooltipUrl, nil, true)
 	if err != nil {
-		return RequisiteExpression{""}, err
+		return RequisiteExpression{""}, nil, err
 	}
 
-	// Required
-	request.Header.Add("X-Requested-With", "XMLHttpRequest")
+	requisiteExpression, err := ParseRequisiteExpression(page)
+	return requisiteExpression, page, err
+}
 
-	response, err := http.DefaultClient.Do(request)
-	if err != nil {
-		return RequisiteExpression{""}, err
-	}
-	defer response.Body.Close()
+// Pages in an unexpected format are returned as errors, even those the parser would panic on
+func ParseRequisiteExpression(page []byte) (requisiteExpression RequisiteExpression, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			requisiteExpression, err = RequisiteExpression{""}, fmt.Errorf("Unexpected class detail tooltip format: %v", r)
+		}
+	}()
 
-	document, err := goquery.NewDocumentFromReader(response.Body)
+	document, err := goquery.NewDocumentFromReader(bytes.NewReader(page))
 	if err != nil {
 		return RequisiteExpression{""}, err
 	}
@@ -0,0 +1,242 @@
+package courses
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/brequin/brequin/scrape/db"
+)
+
+var sectionNamePattern = regexp.MustCompile(`^([A-Za-z]+)\s+(\S+)$`)
+var timePattern = regexp.MustCompile(`(?i)^(\d{1,2})(?::(\d{2}))?\s*(am|pm)$`)
+
+var takenPattern = regexp.MustCompile(`(?i)(\d+)\s+of\s+(\d+)\s+taken`)
+var fullPattern = regexp.MustCompile(`(?i)full\s*\((\d+)\)`)
+var overEnrolledPattern = regexp.MustCompile(`(?i)over\s*enrolled\s+by\s+(\d+)`)
+var waitlistedPattern = regexp.MustCompile(`(?i)(\d+)\s+waitlisted`)
+
+// Everything read from a course summary's section rows
+type SectionRows struct {
+	Sections            []db.Section
+	Meetings            []db.Meeting
+	EnrollmentSnapshots []db.EnrollmentSnapshot
+	Instructors         []db.Instructor
+	SectionInstructors  []db.SectionInstructor
+	FinalExams          []db.FinalExam
+	OfferingDetails     []db.OfferingDetails
+
+	// Class detail pages of lectures, which list final exams and details of the offering
+	classDetails []classDetailLink
+}
+
+type classDetailLink struct {
+	sectionId string
+	path      string
+}
+
+func (r *SectionRows) append(other SectionRows) {
+	r.Sections = append(r.Sections, other.Sections...)
+	r.Meetings = append(r.Meetings, other.Meetings...)
+	r.EnrollmentSnapshots = append(r.EnrollmentSnapshots, other.EnrollmentSnapshots...)
+	r.Instructors = append(r.Instructors, other.Instructors...)
+	r.SectionInstructors = append(r.SectionInstructors, other.SectionInstructors...)
+	r.FinalExams = append(r.FinalExams, other.FinalExams...)
+	r.OfferingDetails = append(r.OfferingDetails, other.OfferingDetails...)
+	r.classDetails = append(r.classDetails, other.classDetails...)
+}
+
+// Lines of a column's text as separated by line breaks and block elements
+func columnLines(column *goquery.Selection) []string {
+	column = column.Clone()
+	column.Find("br").ReplaceWithHtml("\n")
+	column.Find("p, div").Each(func(i int, block *goquery.Selection) {
+		block.AppendHtml("\n")
+	})
+
+	var lines []string
+	for _, line := range strings.Split(column.Text(), "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// Finds a column by its id, falling back to its class for rows without ids
+func sectionColumn(classInfo *goquery.Selection, fakeClassId, idSuffix, class string) *goquery.Selection {
+	column := classInfo.Find("div#" + fakeClassId + idSuffix)
+	if column.Length() == 0 {
+		column = classInfo.Find("div." + class)
+	}
+	return column.First()
+}
+
+// Converts times such as "9am" and "11:50pm" to 24-hour HH:MM
+func parseMeetingTime(text string) (string, bool) {
+	match := timePattern.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return "", false
+	}
+
+	var hour int
+	fmt.Sscan(match[1], &hour)
+	minute := match[2]
+	if minute == "" {
+		minute = "00"
+	}
+	if hour == 12 {
+		hour = 0
+	}
+	if strings.EqualFold(match[3], "pm") {
+		hour += 12
+	}
+	return fmt.Sprintf("%02d:%v", hour, minute), true
+}
+
+// Rooms contain digits, so "Boelter Hall 3400" is building "Boelter Hall" and room "3400", and "Online" is neither
+func parseLocation(location string) (string, string) {
+	fields := strings.Fields(location)
+	if len(fields) < 2 || !strings.ContainsFunc(fields[len(fields)-1], unicode.IsDigit) {
+		return "", ""
+	}
+	return strings.Join(fields[:len(fields)-1], " "), fields[len(fields)-1]
+}
+
+func parseMeetings(sectionId string, days, times, locations []string) []db.Meeting {
+	count := max(len(days), len(times), len(locations))
+
+	var meetings []db.Meeting
+	for i := 0; i < count; i++ {
+		meeting := db.Meeting{SectionId: sectionId, Position: i}
+		if i < len(days) {
+			meeting.Days = days[i]
+		}
+		if i < len(times) {
+			start, end, found := strings.Cut(times[i], "-")
+			if found {
+				startTime, startOk := parseMeetingTime(start)
+				endTime, endOk := parseMeetingTime(end)
+				if startOk && endOk {
+					meeting.StartTime, meeting.EndTime = startTime, endTime
+				}
+			}
+		}
+		if i < len(locations) {
+			meeting.Location = locations[i]
+			meeting.Building, meeting.Room = parseLocation(locations[i])
+		}
+		meetings = append(meetings, meeting)
+	}
+	return meetings
+}
+
+// Reads seats from status text such as "Open 12 of 40 Taken" or "Closed Class Full (40), Over Enrolled By 2"
+// and waitlist text such as "3 of 10 Taken", "Waitlist Full (10)" or "No Waitlist"
+func parseEnrollment(sectionId string, observedAt time.Time, status, waitlist []string) db.EnrollmentSnapshot {
+	snapshot := db.EnrollmentSnapshot{SectionId: sectionId, ObservedAt: observedAt}
+
+	statusText := strings.Join(status, " ")
+	if fields := strings.Fields(statusText); len(fields) > 0 {
+		snapshot.Status = fields[0]
+	}
+	if match := takenPattern.FindStringSubmatch(statusText); match != nil {
+		snapshot.Enrolled, _ = strconv.Atoi(match[1])
+		snapshot.Capacity, _ = strconv.Atoi(match[2])
+	} else if match := fullPattern.FindStringSubmatch(statusText); match != nil {
+		snapshot.Capacity, _ = strconv.Atoi(match[1])
+		snapshot.Enrolled = snapshot.Capacity
+	}
+	if match := overEnrolledPattern.FindStringSubmatch(statusText); match != nil {
+		overEnrolled, _ := strconv.Atoi(match[1])
+		snapshot.Enrolled += overEnrolled
+	}
+
+	waitlistText := strings.Join(waitlist, " ")
+	if match := takenPattern.FindStringSubmatch(waitlistText); match != nil {
+		snapshot.Waitlisted, _ = strconv.Atoi(match[1])
+		snapshot.WaitlistCapacity, _ = strconv.Atoi(match[2])
+	} else if match := fullPattern.FindStringSubmatch(waitlistText); match != nil {
+		snapshot.WaitlistCapacity, _ = strconv.Atoi(match[1])
+		snapshot.Waitlisted = snapshot.WaitlistCapacity
+	} else if match := waitlistedPattern.FindStringSubmatch(statusText); match != nil {
+		snapshot.Waitlisted, _ = strconv.Atoi(match[1])
+	}
+
+	return snapshot
+}
+
+// Parses the sections listed on a course summary page, with their enrollment as observed at observedAt.
+// Secondary rows, such as discussions and labs, belong to the primary row before them.
+func ParseSections(document *goquery.Document, observedAt time.Time, quarterCode, subjectAreaCode, catalogNumber string) SectionRows {
+	var rows SectionRows
+	var parentId *string
+
+	document.Find("div.class-info").Each(func(i int, classInfo *goquery.Selection) {
+		fakeClassId, _ := classInfo.Attr("id")
+
+		sectionLink := sectionColumn(classInfo, fakeClassId, "-section", "sectionColumn").Find("a").First()
+		match := sectionNamePattern.FindStringSubmatch(strings.Join(strings.Fields(sectionLink.Text()), " "))
+		if match == nil {
+			log.Printf("Unable to determine section of class: %v %v\n", subjectAreaCode, catalogNumber)
+			return
+		}
+
+		instructorNames := columnLines(sectionColumn(classInfo, fakeClassId, "-instructor_data", "instructorColumn"))
+		section := db.Section{
+			Id:              db.SectionId(quarterCode, subjectAreaCode, catalogNumber, match[1], match[2]),
+			QuarterCode:     quarterCode,
+			SubjectAreaCode: subjectAreaCode,
+			CatalogNumber:   catalogNumber,
+			Activity:        match[1],
+			Number:          match[2],
+			Units:           strings.Join(columnLines(sectionColumn(classInfo, fakeClassId, "-units_data", "unitsColumn")), " "),
+			Instructors:     strings.Join(instructorNames, "; "),
+		}
+		href, hasHref := sectionLink.Attr("href")
+		if hasHref {
+			if detailUrl, err := url.Parse(href); err == nil {
+				section.ClassId = strings.TrimSpace(detailUrl.Query().Get("class_id"))
+			}
+		}
+
+		if classInfo.HasClass("secondary-row") {
+			section.ParentId = parentId
+		} else {
+			id := section.Id
+			parentId = &id
+			if hasHref {
+				rows.classDetails = append(rows.classDetails, classDetailLink{sectionId: section.Id, path: href})
+			}
+		}
+
+		days := columnLines(sectionColumn(classInfo, fakeClassId, "-days_data", "dayColumn"))
+		times := columnLines(sectionColumn(classInfo, fakeClassId, "-time_data", "timeColumn"))
+		locations := columnLines(sectionColumn(classInfo, fakeClassId, "-location", "locationColumn"))
+		status := columnLines(sectionColumn(classInfo, fakeClassId, "-status_data", "statusColumn"))
+		waitlist := columnLines(sectionColumn(classInfo, fakeClassId, "-waitlist_data", "waitlistColumn"))
+
+		rows.Sections = append(rows.Sections, section)
+		rows.Meetings = append(rows.Meetings, parseMeetings(section.Id, days, times, locations)...)
+		if len(status) > 0 {
+			rows.EnrollmentSnapshots = append(rows.EnrollmentSnapshots, parseEnrollment(section.Id, observedAt, status, waitlist))
+		}
+		for _, instructorName := range instructorNames {
+			instructor, ok := ParseInstructor(instructorName)
+			if !ok {
+				continue
+			}
+			rows.Instructors = append(rows.Instructors, instructor)
+			rows.SectionInstructors = append(rows.SectionInstructors, db.SectionInstructor{SectionId: section.Id, InstructorId: instructor.Id})
+		}
+	})
+
+	return rows
+}
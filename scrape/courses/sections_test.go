@@ -0,0 +1,111 @@
+package courses
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/brequin/brequin/scrape/db"
+)
+
+func readFixture(t *testing.T, name string) *goquery.Document {
+	t.Helper()
+
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	document, err := goquery.NewDocumentFromReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return document
+}
+
+func TestParseSections(t *testing.T) {
+	rows := ParseSections(readFixture(t, "course_summary.html"), time.Now(), "24F", "COM SCI", "31")
+
+	lectureId := db.SectionId("24F", "COM SCI", "31", "Lec", "1")
+	discussionId := db.SectionId("24F", "COM SCI", "31", "Dis", "1A")
+	wantSections := []db.Section{
+		{Id: lectureId, QuarterCode: "24F", SubjectAreaCode: "COM SCI", CatalogNumber: "31", Activity: "Lec", Number: "1", ClassId: "187003200", Units: "4.0", Instructors: "Smith, John; Staff"},
+		{Id: discussionId, QuarterCode: "24F", SubjectAreaCode: "COM SCI", CatalogNumber: "31", Activity: "Dis", Number: "1A", ClassId: "187003201", Units: "0.0", Instructors: "TA", ParentId: &lectureId},
+	}
+	if !reflect.DeepEqual(rows.Sections, wantSections) {
+		t.Errorf("Sections = %+v; want %+v", rows.Sections, wantSections)
+	}
+
+	wantMeetings := []db.Meeting{
+		{SectionId: lectureId, Position: 0, Days: "MW", StartTime: "10:00", EndTime: "11:50", Location: "Boelter Hall 3400", Building: "Boelter Hall", Room: "3400"},
+		{SectionId: lectureId, Position: 1, Days: "F", StartTime: "14:00", EndTime: "14:50", Location: "Online"},
+		{SectionId: discussionId, Position: 0, Days: "R", StartTime: "09:00", EndTime: "09:50", Location: "Dodd Hall 161", Building: "Dodd Hall", Room: "161"},
+	}
+	if !reflect.DeepEqual(rows.Meetings, wantMeetings) {
+		t.Errorf("Meetings = %+v; want %+v", rows.Meetings, wantMeetings)
+	}
+
+	// Only lectures link the class detail pages that are fetched
+	if len(rows.classDetails) != 1 || rows.classDetails[0].sectionId != lectureId {
+		t.Errorf("Class details = %+v; want the lecture's only", rows.classDetails)
+	}
+}
+
+func TestParseMeetingTime(t *testing.T) {
+	tests := []struct {
+		text string
+		time string
+		ok   bool
+	}{
+		{"9am", "09:00", true},
+		{"11:50pm", "23:50", true},
+		{"12pm", "12:00", true},
+		{"12:30am", "00:30", true},
+		{" 2PM ", "14:00", true},
+		{"TBA", "", false},
+	}
+
+	for _, test := range tests {
+		time, ok := parseMeetingTime(test.text)
+		if time != test.time || ok != test.ok {
+			t.Errorf("parseMeetingTime(%q) = %q, %v; want %q, %v", test.text, time, ok, test.time, test.ok)
+		}
+	}
+}
+
+func TestParseSectionsEnrollment(t *testing.T) {
+	observedAt := time.Date(2024, time.October, 1, 12, 0, 0, 0, time.UTC)
+	rows := ParseSections(readFixture(t, "course_summary.html"), observedAt, "24F", "COM SCI", "31")
+
+	want := []db.EnrollmentSnapshot{
+		{SectionId: db.SectionId("24F", "COM SCI", "31", "Lec", "1"), ObservedAt: observedAt, Status: "Open", Enrolled: 12, Capacity: 40},
+		{SectionId: db.SectionId("24F", "COM SCI", "31", "Dis", "1A"), ObservedAt: observedAt, Status: "Closed", Enrolled: 27, Capacity: 25, Waitlisted: 3, WaitlistCapacity: 10},
+	}
+	if !reflect.DeepEqual(rows.EnrollmentSnapshots, want) {
+		t.Errorf("EnrollmentSnapshots = %+v; want %+v", rows.EnrollmentSnapshots, want)
+	}
+}
+
+func TestParseEnrollment(t *testing.T) {
+	tests := []struct {
+		status   []string
+		waitlist []string
+		want     db.EnrollmentSnapshot
+	}{
+		{[]string{"Open", "12 of 40 Taken"}, []string{"No Waitlist"}, db.EnrollmentSnapshot{Status: "Open", Enrolled: 12, Capacity: 40}},
+		{[]string{"Closed", "Class Full (40)"}, []string{"Waitlist Full (10)"}, db.EnrollmentSnapshot{Status: "Closed", Enrolled: 40, Capacity: 40, Waitlisted: 10, WaitlistCapacity: 10}},
+		{[]string{"Closed", "Class Full (40), Over Enrolled By 2"}, []string{"3 of 10 Taken"}, db.EnrollmentSnapshot{Status: "Closed", Enrolled: 42, Capacity: 40, Waitlisted: 3, WaitlistCapacity: 10}},
+		{[]string{"Waitlist", "Class Full (40)", "5 Waitlisted"}, nil, db.EnrollmentSnapshot{Status: "Waitlist", Enrolled: 40, Capacity: 40, Waitlisted: 5}},
+		{[]string{"Cancelled"}, nil, db.EnrollmentSnapshot{Status: "Cancelled"}},
+	}
+
+	for _, test := range tests {
+		if got := parseEnrollment("", time.Time{}, test.status, test.waitlist); got != test.want {
+			t.Errorf("parseEnrollment(%q, %q) = %+v; want %+v", test.status, test.waitlist, got, test.want)
+		}
+	}
+}
@@ -1,14 +1,56 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-type Database struct {
-	Pool *pgxpool.Pool
+// Store backed by Postgres
+type Postgres struct {
+	Pool  *pgxpool.Pool
+	RunId *int64 // Tags written rows when set
+
+	tx pgx.Tx
+}
+
+// Satisfied by both the pool and a transaction
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+func (d *Postgres) conn() querier {
+	if d.tx != nil {
+		return d.tx
+	}
+	return d.Pool
+}
+
+// The copy shares the pool and any open transaction
+func (d *Postgres) WithRun(runId int64) Store {
+	return &Postgres{Pool: d.Pool, RunId: &runId, tx: d.tx}
+}
+
+// Commits if fn succeeds and rolls back otherwise, including when ctx is cancelled
+func (d *Postgres) InTx(ctx context.Context, fn func(tx Store) error) error {
+	return pgx.BeginFunc(ctx, d.conn(), func(tx pgx.Tx) error {
+		return fn(&Postgres{Pool: d.Pool, RunId: d.RunId, tx: tx})
+	})
+}
+
+func (d *Postgres) Close() error {
+	d.Pool.Close()
+	return nil
 }
 
 func Flag(b bool) byte {
@@ -23,3 +65,51 @@ func ValueNodeId(subjectAreaCode, catalogNumber string) string {
 	const idTemplate = "%v#%v"
 	return fmt.Sprintf(idTemplate, subjectAreaCode, catalogNumber)
 }
+
+func SectionId(quarterCode, subjectAreaCode, catalogNumber, activity, number string) string {
+	const idTemplate = "%v#%v#%v#%v %v"
+	return fmt.Sprintf(idTemplate, quarterCode, subjectAreaCode, catalogNumber, activity, number)
+}
+
+// Case-insensitive, so listings differing only in case are the same instructor
+func InstructorId(lastName, firstInitial string) string {
+	const idTemplate = "%v#%v"
+	return fmt.Sprintf(idTemplate, strings.ToLower(lastName), strings.ToLower(firstInitial))
+}
+
+// Mirrors the quarter_rank SQL function; ranks sort chronologically as strings
+func QuarterRank(code string) string {
+	if len(code) < 3 {
+		return ""
+	}
+
+	year := code[:2]
+	switch code[len(code)-1] {
+	case 'W':
+		return year + "0"
+	case 'S':
+		return year + "1"
+	case '1':
+		return year + "2"
+	case '2':
+		return year + "3"
+	case 'F':
+		return year + "4"
+	}
+	return ""
+}
+
+// Code of the quarter in session at t, with summer sessions counted as quarter 1
+func CurrentQuarterCode(t time.Time) string {
+	year := fmt.Sprintf("%02d", t.Year()%100)
+	switch {
+	case t.Month() <= time.March:
+		return year + "W"
+	case t.Month() <= time.June:
+		return year + "S"
+	case t.Month() <= time.August:
+		return year + "1"
+	default:
+		return year + "F"
+	}
+}
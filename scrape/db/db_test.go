@@ -0,0 +1,16 @@
+package db
+
+import "testing"
+
+func TestQuarterRank(t *testing.T) {
+	for _, codes := range [][2]string{{"24W", "24S"}, {"24S", "241"}, {"241", "242"}, {"242", "24F"}, {"24F", "25W"}} {
+		if QuarterRank(codes[0]) >= QuarterRank(codes[1]) {
+			t.Errorf("QuarterRank(%v) = %q is not before QuarterRank(%v) = %q", codes[0], QuarterRank(codes[0]), codes[1], QuarterRank(codes[1]))
+		}
+	}
+	for _, code := range []string{"", "24", "24X"} {
+		if rank := QuarterRank(code); rank != "" {
+			t.Errorf("QuarterRank(%q) = %q; want no rank", code, rank)
+		}
+	}
+}
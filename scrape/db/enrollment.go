@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const insertEnrollmentSnapshot = `INSERT INTO enrollment_snapshots (section_id, observed_at, status, enrolled, capacity, waitlisted, waitlist_capacity, run_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (section_id, observed_at) DO NOTHING`
+const listEnrollmentSnapshots = `SELECT enrollment_snapshots.section_id, enrollment_snapshots.observed_at, enrollment_snapshots.status, enrollment_snapshots.enrolled, enrollment_snapshots.capacity, enrollment_snapshots.waitlisted, enrollment_snapshots.waitlist_capacity FROM enrollment_snapshots JOIN sections ON enrollment_snapshots.section_id = sections.id WHERE sections.quarter_code = $1 AND sections.subject_area_code = $2 AND enrollment_snapshots.observed_at >= $3 AND enrollment_snapshots.observed_at < $4 ORDER BY enrollment_snapshots.section_id, enrollment_snapshots.observed_at`
+
+// Snapshots are only ever appended, so repeated scrapes build a time series per section
+func (d *Postgres) InsertEnrollmentSnapshots(ctx context.Context, snapshots []EnrollmentSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+
+	batch := pgx.Batch{}
+	var queuedQueries []*pgx.QueuedQuery
+
+	for _, snapshot := range snapshots {
+		queuedQueries = append(queuedQueries, batch.Queue(insertEnrollmentSnapshot, snapshot.SectionId, snapshot.ObservedAt, snapshot.Status, snapshot.Enrolled, snapshot.Capacity, snapshot.Waitlisted, snapshot.WaitlistCapacity, d.RunId))
+	}
+
+	for _, queuedQuery := range queuedQueries {
+		queuedQuery.Exec(insertCallback)
+	}
+
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Ordered by section, then observation, so each section's fill-rate curve is contiguous
+func (d *Postgres) ListEnrollmentSnapshots(ctx context.Context, quarterCode, subjectAreaCode string, from, until time.Time) ([]EnrollmentSnapshot, error) {
+	sql := listEnrollmentSnapshots
+	rows, err := d.conn().Query(ctx, sql, quarterCode, subjectAreaCode, from, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []EnrollmentSnapshot
+	for rows.Next() {
+		var snapshot EnrollmentSnapshot
+		if err := rows.Scan(&snapshot.SectionId, &snapshot.ObservedAt, &snapshot.Status, &snapshot.Enrolled, &snapshot.Capacity, &snapshot.Waitlisted, &snapshot.WaitlistCapacity); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const insertCourseEquivalence = `INSERT INTO course_equivalences (subject_area_code, catalog_number, equivalent_subject_area_code, equivalent_catalog_number, kind, first_run_id, last_run_id) VALUES ($1, $2, $3, $4, $5, $6, $6) ON CONFLICT (subject_area_code, catalog_number, equivalent_subject_area_code, equivalent_catalog_number) DO UPDATE SET kind=EXCLUDED.kind, last_run_id=COALESCE(EXCLUDED.last_run_id, course_equivalences.last_run_id)`
+
+// UNION rather than UNION ALL ends the recursion at courses already reached
+const listCourseEquivalents = `WITH RECURSIVE equivalents (subject_area_code, catalog_number) AS (
+  SELECT CAST($1 AS text), CAST($2 AS text)
+  UNION
+  SELECT course_equivalences.equivalent_subject_area_code, course_equivalences.equivalent_catalog_number FROM course_equivalences JOIN equivalents ON course_equivalences.subject_area_code = equivalents.subject_area_code AND course_equivalences.catalog_number = equivalents.catalog_number
+)
+SELECT subject_area_code, catalog_number FROM equivalents WHERE NOT (subject_area_code = $1 AND catalog_number = $2) ORDER BY subject_area_code, catalog_number`
+
+func (d *Postgres) InsertCourseEquivalences(ctx context.Context, courseEquivalences []CourseEquivalence) error {
+	if len(courseEquivalences) == 0 {
+		return nil
+	}
+
+	batch := pgx.Batch{}
+	var queuedQueries []*pgx.QueuedQuery
+
+	for _, e := range courseEquivalences {
+		queuedQueries = append(queuedQueries, batch.Queue(insertCourseEquivalence, e.SubjectAreaCode, e.CatalogNumber, e.EquivalentSubjectAreaCode, e.EquivalentCatalogNumber, e.Kind, d.RunId))
+	}
+
+	for _, queuedQuery := range queuedQueries {
+		queuedQuery.Exec(insertCallback)
+	}
+
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Node ids are derived from the course, since equivalent courses need not have been scraped
+func (d *Postgres) ListCourseEquivalents(ctx context.Context, course Course) ([]Course, error) {
+	sql := listCourseEquivalents
+	rows, err := d.conn().Query(ctx, sql, course.SubjectAreaCode, course.CatalogNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var courses []Course
+	for rows.Next() {
+		var equivalent Course
+		if err := rows.Scan(&equivalent.SubjectAreaCode, &equivalent.CatalogNumber); err != nil {
+			return nil, err
+		}
+		equivalent.NodeId = ValueNodeId(equivalent.SubjectAreaCode, equivalent.CatalogNumber)
+		courses = append(courses, equivalent)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return courses, nil
+}
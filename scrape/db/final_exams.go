@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const insertFinalExam = `INSERT INTO final_exams (section_id, date, start_time, end_time, location, building, room, first_run_id, last_run_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8) ON CONFLICT (section_id) DO UPDATE SET date=EXCLUDED.date, start_time=EXCLUDED.start_time, end_time=EXCLUDED.end_time, location=EXCLUDED.location, building=EXCLUDED.building, room=EXCLUDED.room, last_run_id=COALESCE(EXCLUDED.last_run_id, final_exams.last_run_id)`
+const getFinalExam = `SELECT section_id, date, start_time, end_time, location, building, room FROM final_exams WHERE section_id = $1`
+
+func finalExamArgs(finalExam FinalExam, runId *int64) []any {
+	return []any{finalExam.SectionId, finalExam.Date, finalExam.StartTime, finalExam.EndTime, finalExam.Location, finalExam.Building, finalExam.Room, runId}
+}
+
+func (d *Postgres) InsertFinalExams(ctx context.Context, finalExams []FinalExam) error {
+	if len(finalExams) == 0 {
+		return nil
+	}
+
+	batch := pgx.Batch{}
+	var queuedQueries []*pgx.QueuedQuery
+
+	for _, finalExam := range finalExams {
+		queuedQueries = append(queuedQueries, batch.Queue(insertFinalExam, finalExamArgs(finalExam, d.RunId)...))
+	}
+
+	for _, queuedQuery := range queuedQueries {
+		queuedQuery.Exec(insertCallback)
+	}
+
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Postgres) ListFinalExams(ctx context.Context, sectionIds []string) ([]FinalExam, error) {
+	var finalExams []FinalExam
+	for _, sectionId := range sectionIds {
+		var finalExam FinalExam
+		err := d.conn().QueryRow(ctx, getFinalExam, sectionId).Scan(&finalExam.SectionId, &finalExam.Date, &finalExam.StartTime, &finalExam.EndTime, &finalExam.Location, &finalExam.Building, &finalExam.Room)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		finalExams = append(finalExams, finalExam)
+	}
+	return finalExams, nil
+}
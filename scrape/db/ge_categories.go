@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const insertCourseGeCategory = `INSERT INTO course_ge_categories (quarter_code, subject_area_code, catalog_number, foundation, category, first_run_id, last_run_id) VALUES ($1, $2, $3, $4, $5, $6, $6) ON CONFLICT (quarter_code, subject_area_code, catalog_number, foundation, category) DO UPDATE SET last_run_id=COALESCE(EXCLUDED.last_run_id, course_ge_categories.last_run_id)`
+const listCourseGeCategories = `SELECT quarter_code, subject_area_code, catalog_number, foundation, category FROM course_ge_categories WHERE quarter_code = $1 AND subject_area_code = $2 ORDER BY catalog_number, foundation, category`
+const listGeQuarterCourses = `SELECT DISTINCT quarter_courses.subject_area_code, quarter_courses.catalog_number FROM quarter_courses JOIN course_ge_categories ON course_ge_categories.quarter_code = quarter_courses.quarter_code AND course_ge_categories.subject_area_code = quarter_courses.subject_area_code AND course_ge_categories.catalog_number = quarter_courses.catalog_number WHERE quarter_courses.quarter_code = $1 AND quarter_courses.subject_area_code = $2 AND course_ge_categories.foundation = $3 AND ($4 = '' OR course_ge_categories.category = $4) ORDER BY quarter_courses.catalog_number`
+
+func (d *Postgres) InsertCourseGeCategories(ctx context.Context, courseGeCategories []CourseGeCategory) error {
+	if len(courseGeCategories) == 0 {
+		return nil
+	}
+
+	batch := pgx.Batch{}
+	var queuedQueries []*pgx.QueuedQuery
+
+	for _, c := range courseGeCategories {
+		queuedQueries = append(queuedQueries, batch.Queue(insertCourseGeCategory, c.QuarterCode, c.SubjectAreaCode, c.CatalogNumber, c.Foundation, c.Category, d.RunId))
+	}
+
+	for _, queuedQuery := range queuedQueries {
+		queuedQuery.Exec(insertCallback)
+	}
+
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Postgres) ListCourseGeCategories(ctx context.Context, quarter Quarter, subjectArea SubjectArea) ([]CourseGeCategory, error) {
+	sql := listCourseGeCategories
+	rows, err := d.conn().Query(ctx, sql, quarter.Code, subjectArea.Code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var courseGeCategories []CourseGeCategory
+	for rows.Next() {
+		var c CourseGeCategory
+		if err := rows.Scan(&c.QuarterCode, &c.SubjectAreaCode, &c.CatalogNumber, &c.Foundation, &c.Category); err != nil {
+			return nil, err
+		}
+		courseGeCategories = append(courseGeCategories, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return courseGeCategories, nil
+}
+
+func (d *Postgres) ListGeQuarterCourses(ctx context.Context, quarter Quarter, subjectArea SubjectArea, foundation, category string) ([]Course, error) {
+	sql := listGeQuarterCourses
+	rows, err := d.conn().Query(ctx, sql, quarter.Code, subjectArea.Code, foundation, category)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var courses []Course
+	for rows.Next() {
+		var course Course
+		if err := rows.Scan(&course.SubjectAreaCode, &course.CatalogNumber); err != nil {
+			return nil, err
+		}
+		course.NodeId = ValueNodeId(course.SubjectAreaCode, course.CatalogNumber)
+		courses = append(courses, course)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return courses, nil
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const insertInstructor = `INSERT INTO instructors (id, name, first_run_id, last_run_id) VALUES ($1, $2, $3, $3) ON CONFLICT (id) DO UPDATE SET name=CASE WHEN length(EXCLUDED.name) > length(instructors.name) THEN EXCLUDED.name ELSE instructors.name END, last_run_id=COALESCE(EXCLUDED.last_run_id, instructors.last_run_id)`
+const insertSectionInstructor = `INSERT INTO section_instructors (section_id, instructor_id, first_run_id, last_run_id) VALUES ($1, $2, $3, $3) ON CONFLICT (section_id, instructor_id) DO UPDATE SET last_run_id=COALESCE(EXCLUDED.last_run_id, section_instructors.last_run_id)`
+
+const listTeachingsTemplate = `SELECT instructors.id, instructors.name, sections.quarter_code, sections.subject_area_code, sections.catalog_number FROM section_instructors JOIN instructors ON section_instructors.instructor_id = instructors.id JOIN sections ON section_instructors.section_id = sections.id WHERE %v GROUP BY instructors.id, instructors.name, sections.quarter_code, sections.subject_area_code, sections.catalog_number ORDER BY quarter_rank(sections.quarter_code), sections.subject_area_code, sections.catalog_number, instructors.name`
+
+var listCourseTeachings = fmt.Sprintf(listTeachingsTemplate, `sections.subject_area_code = $1 AND sections.catalog_number = $2`)
+var listInstructorTeachings = fmt.Sprintf(listTeachingsTemplate, `instructors.id = $1`)
+
+// The same instructor may be listed more than once, under more or less complete names
+func (d *Postgres) InsertInstructors(ctx context.Context, instructors []Instructor) error {
+	if len(instructors) == 0 {
+		return nil
+	}
+
+	batch := pgx.Batch{}
+	var queuedQueries []*pgx.QueuedQuery
+
+	for _, instructor := range instructors {
+		queuedQueries = append(queuedQueries, batch.Queue(insertInstructor, instructor.Id, instructor.Name, d.RunId))
+	}
+
+	for _, queuedQuery := range queuedQueries {
+		queuedQuery.Exec(insertCallback)
+	}
+
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Postgres) InsertSectionInstructors(ctx context.Context, sectionInstructors []SectionInstructor) error {
+	if len(sectionInstructors) == 0 {
+		return nil
+	}
+
+	batch := pgx.Batch{}
+	var queuedQueries []*pgx.QueuedQuery
+
+	for _, sectionInstructor := range sectionInstructors {
+		queuedQueries = append(queuedQueries, batch.Queue(insertSectionInstructor, sectionInstructor.SectionId, sectionInstructor.InstructorId, d.RunId))
+	}
+
+	for _, queuedQuery := range queuedQueries {
+		queuedQuery.Exec(insertCallback)
+	}
+
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Postgres) listTeachings(ctx context.Context, sql string, args ...any) ([]Teaching, error) {
+	rows, err := d.conn().Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teachings []Teaching
+	for rows.Next() {
+		var teaching Teaching
+		if err := rows.Scan(&teaching.InstructorId, &teaching.InstructorName, &teaching.QuarterCode, &teaching.SubjectAreaCode, &teaching.CatalogNumber); err != nil {
+			return nil, err
+		}
+		teachings = append(teachings, teaching)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return teachings, nil
+}
+
+func (d *Postgres) ListCourseTeachings(ctx context.Context, course Course) ([]Teaching, error) {
+	return d.listTeachings(ctx, listCourseTeachings, course.SubjectAreaCode, course.CatalogNumber)
+}
+
+func (d *Postgres) ListInstructorTeachings(ctx context.Context, instructorId string) ([]Teaching, error) {
+	return d.listTeachings(ctx, listInstructorTeachings, instructorId)
+}
@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrLocked = errors.New("Lock is held by another run")
+
+const tryAdvisoryLock = `SELECT pg_try_advisory_lock(hashtext($1))`
+const advisoryUnlock = `SELECT pg_advisory_unlock(hashtext($1))`
+
+// Advisory locks belong to the session, so the lock holds a connection until unlocked, and a crashed process's lock ends with its connection
+func (d *Postgres) TryLock(ctx context.Context, name string) (func(), error) {
+	conn, err := d.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var locked bool
+	if err := conn.QueryRow(ctx, tryAdvisoryLock, name).Scan(&locked); err != nil {
+		conn.Release()
+		return nil, err
+	}
+	if !locked {
+		conn.Release()
+		return nil, ErrLocked
+	}
+
+	return func() {
+		// Closing the connection releases the lock if unlocking fails
+		if _, err := conn.Exec(context.WithoutCancel(ctx), advisoryUnlock, name); err != nil {
+			conn.Conn().Close(context.WithoutCancel(ctx))
+		}
+		conn.Release()
+	}, nil
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSQLiteLockExpires(t *testing.T) {
+	ctx := context.Background()
+	d, err := OpenSQLite(filepath.Join(t.TempDir(), "brequin.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+	if _, err := d.Migrate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.TryLock(ctx, "scrape"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.TryLock(ctx, "scrape"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("TryLock while held = %v; want ErrLocked", err)
+	}
+
+	// As if the holder crashed without unlocking
+	stale := time.Now().UTC().Add(-2 * sqliteLockExpiry).Format(time.RFC3339Nano)
+	if _, err := d.DB.ExecContext(ctx, `UPDATE scrape_locks SET pid = 0, refreshed_at = $1`, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := d.TryLock(ctx, "scrape")
+	if err != nil {
+		t.Fatalf("TryLock after expiry = %v; want nil", err)
+	}
+	unlock()
+
+	unlock, err = d.TryLock(ctx, "scrape")
+	if err != nil {
+		t.Fatalf("TryLock after unlock = %v; want nil", err)
+	}
+	unlock()
+}
@@ -0,0 +1,765 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+type courseKey struct {
+	subjectAreaCode string
+	catalogNumber   string
+}
+
+type relationKey struct {
+	sourceId     string
+	targetId     string
+	enforced     string
+	prereq       string
+	coreq        string
+	minimumGrade string
+}
+
+type progressKey struct {
+	stage           string
+	quarterCode     string
+	subjectAreaCode string
+}
+
+type contentHashKey struct {
+	quarterCode     string
+	subjectAreaCode string
+	catalogNumber   string
+}
+
+type meetingKey struct {
+	sectionId string
+	position  int
+}
+
+type enrollmentSnapshotKey struct {
+	sectionId  string
+	observedAt int64
+}
+
+type requisiteObservationKey struct {
+	courseNodeId string
+	quarterCode  string
+}
+
+type memoryState struct {
+	mutex sync.Mutex
+
+	quarters              map[string]Quarter
+	subjectAreas          map[string]SubjectArea
+	quarterSubjectAreas   map[string]map[string]bool
+	nodes                 map[string]Node
+	courses               map[courseKey]Course
+	coursesDetails        map[courseKey]CourseDetails
+	relations             map[relationKey]Relation
+	progress              map[progressKey]ScrapeProgress
+	contentHashes         map[contentHashKey]ContentHash
+	sections              map[string]Section
+	meetings              map[meetingKey]Meeting
+	enrollmentSnapshots   map[enrollmentSnapshotKey]EnrollmentSnapshot
+	quarterCourses        map[QuarterCourse]bool
+	courseGeCategories    map[CourseGeCategory]bool
+	courseEquivalences    map[courseKey]map[courseKey]EquivalenceKind
+	requisiteObservations map[requisiteObservationKey][]Relation
+	instructors           map[string]Instructor
+	sectionInstructors    map[SectionInstructor]bool
+	finalExams            map[string]FinalExam
+	offeringsDetails      map[QuarterCourse]OfferingDetails
+	runs                  []ScrapeRun
+	locks                 map[string]bool
+}
+
+// Store kept in memory and lost on exit, for tests and for pipeline runs without a database.
+// Rows are not tagged with scrape runs.
+type Memory struct {
+	state *memoryState
+
+	// Writes queued by an open transaction, applied together on commit
+	pending *[]func()
+}
+
+func NewMemory() *Memory {
+	return &Memory{state: &memoryState{
+		quarters:              make(map[string]Quarter),
+		subjectAreas:          make(map[string]SubjectArea),
+		quarterSubjectAreas:   make(map[string]map[string]bool),
+		nodes:                 make(map[string]Node),
+		courses:               make(map[courseKey]Course),
+		coursesDetails:        make(map[courseKey]CourseDetails),
+		relations:             make(map[relationKey]Relation),
+		progress:              make(map[progressKey]ScrapeProgress),
+		contentHashes:         make(map[contentHashKey]ContentHash),
+		sections:              make(map[string]Section),
+		meetings:              make(map[meetingKey]Meeting),
+		enrollmentSnapshots:   make(map[enrollmentSnapshotKey]EnrollmentSnapshot),
+		quarterCourses:        make(map[QuarterCourse]bool),
+		courseGeCategories:    make(map[CourseGeCategory]bool),
+		courseEquivalences:    make(map[courseKey]map[courseKey]EquivalenceKind),
+		requisiteObservations: make(map[requisiteObservationKey][]Relation),
+		instructors:           make(map[string]Instructor),
+		sectionInstructors:    make(map[SectionInstructor]bool),
+		finalExams:            make(map[string]FinalExam),
+		offeringsDetails:      make(map[QuarterCourse]OfferingDetails),
+		locks:                 make(map[string]bool),
+	}}
+}
+
+// Applies write now, or on commit inside a transaction
+func (m *Memory) write(write func()) error {
+	if m.pending != nil {
+		*m.pending = append(*m.pending, write)
+		return nil
+	}
+
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	write()
+	return nil
+}
+
+func (m *Memory) WithRun(runId int64) Store {
+	return m
+}
+
+// Reads inside the transaction do not see its own writes
+func (m *Memory) InTx(ctx context.Context, fn func(tx Store) error) error {
+	if m.pending != nil {
+		return fn(m)
+	}
+
+	var pending []func()
+	if err := fn(&Memory{state: m.state, pending: &pending}); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	for _, write := range pending {
+		write()
+	}
+	return nil
+}
+
+func (m *Memory) Migrate(ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+func (m *Memory) Close() error {
+	return nil
+}
+
+func (m *Memory) ListQuarters(ctx context.Context) ([]Quarter, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	var quarters []Quarter
+	for _, quarter := range m.state.quarters {
+		quarters = append(quarters, quarter)
+	}
+	sort.Slice(quarters, func(i, j int) bool {
+		return QuarterRank(quarters[i].Code) < QuarterRank(quarters[j].Code)
+	})
+	return quarters, nil
+}
+
+func (m *Memory) InsertQuarters(ctx context.Context, quarters []Quarter) error {
+	return m.write(func() {
+		for _, quarter := range quarters {
+			if _, exists := m.state.quarters[quarter.Code]; !exists {
+				m.state.quarters[quarter.Code] = quarter
+			}
+		}
+	})
+}
+
+func (m *Memory) ListSubjectAreas(ctx context.Context) ([]SubjectArea, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	var subjectAreas []SubjectArea
+	for _, subjectArea := range m.state.subjectAreas {
+		subjectAreas = append(subjectAreas, subjectArea)
+	}
+	sort.Slice(subjectAreas, func(i, j int) bool {
+		return subjectAreas[i].Code < subjectAreas[j].Code
+	})
+	return subjectAreas, nil
+}
+
+func (m *Memory) InsertSubjectAreas(ctx context.Context, subjectAreas []SubjectArea) error {
+	return m.write(func() {
+		for _, subjectArea := range subjectAreas {
+			if _, exists := m.state.subjectAreas[subjectArea.Code]; !exists {
+				m.state.subjectAreas[subjectArea.Code] = subjectArea
+			}
+		}
+	})
+}
+
+func (m *Memory) ListQuarterSubjectAreas(ctx context.Context, quarter Quarter) ([]SubjectArea, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	var subjectAreas []SubjectArea
+	for subjectAreaCode := range m.state.quarterSubjectAreas[quarter.Code] {
+		subjectAreas = append(subjectAreas, m.state.subjectAreas[subjectAreaCode])
+	}
+	sort.Slice(subjectAreas, func(i, j int) bool {
+		return subjectAreas[i].Code < subjectAreas[j].Code
+	})
+	return subjectAreas, nil
+}
+
+func (m *Memory) InsertQuarterSubjectAreas(ctx context.Context, quarter Quarter, subjectAreas []SubjectArea) error {
+	return m.write(func() {
+		if m.state.quarterSubjectAreas[quarter.Code] == nil {
+			m.state.quarterSubjectAreas[quarter.Code] = make(map[string]bool)
+		}
+		for _, subjectArea := range subjectAreas {
+			m.state.quarterSubjectAreas[quarter.Code][subjectArea.Code] = true
+		}
+	})
+}
+
+func (m *Memory) InsertNodes(ctx context.Context, nodes []Node) error {
+	return m.write(func() {
+		for _, node := range nodes {
+			m.state.nodes[node.Id] = node
+		}
+	})
+}
+
+func (m *Memory) InsertCourses(ctx context.Context, courses []Course) error {
+	return m.write(func() {
+		for _, course := range courses {
+			key := courseKey{course.SubjectAreaCode, course.CatalogNumber}
+			if _, exists := m.state.courses[key]; !exists {
+				m.state.courses[key] = course
+			}
+		}
+	})
+}
+
+func (m *Memory) InsertRelations(ctx context.Context, relations []Relation) error {
+	return m.write(func() {
+		for _, relation := range relations {
+			m.state.relations[relationKeyOf(relation)] = relation
+		}
+	})
+}
+
+func (m *Memory) InsertCoursesDetails(ctx context.Context, coursesDetails []CourseDetails) error {
+	return m.write(func() {
+		for _, courseDetails := range coursesDetails {
+			m.state.coursesDetails[courseKey{courseDetails.SubjectAreaCode, courseDetails.CatalogNumber}] = courseDetails
+		}
+	})
+}
+
+func (m *Memory) ListScrapeProgress(ctx context.Context, stage string) ([]ScrapeProgress, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	var progress []ScrapeProgress
+	for key, p := range m.state.progress {
+		if key.stage == stage {
+			progress = append(progress, p)
+		}
+	}
+	return progress, nil
+}
+
+func (m *Memory) ClearScrapeProgress(ctx context.Context, progress []ScrapeProgress) error {
+	return m.write(func() {
+		for _, p := range progress {
+			delete(m.state.progress, progressKey{p.Stage, p.QuarterCode, p.SubjectAreaCode})
+		}
+	})
+}
+
+func (m *Memory) UpsertScrapeProgress(ctx context.Context, progress ScrapeProgress) error {
+	return m.write(func() {
+		m.state.progress[progressKey{progress.Stage, progress.QuarterCode, progress.SubjectAreaCode}] = progress
+	})
+}
+
+func (m *Memory) ListContentHashes(ctx context.Context, quarterCode, subjectAreaCode string) ([]ContentHash, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	var contentHashes []ContentHash
+	for key, contentHash := range m.state.contentHashes {
+		if key.quarterCode == quarterCode && key.subjectAreaCode == subjectAreaCode {
+			contentHashes = append(contentHashes, contentHash)
+		}
+	}
+	return contentHashes, nil
+}
+
+func (m *Memory) UpsertContentHashes(ctx context.Context, contentHashes []ContentHash) error {
+	return m.write(func() {
+		for _, contentHash := range contentHashes {
+			m.state.contentHashes[contentHashKey{contentHash.QuarterCode, contentHash.SubjectAreaCode, contentHash.CatalogNumber}] = contentHash
+		}
+	})
+}
+
+func (m *Memory) TryLock(ctx context.Context, name string) (func(), error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	if m.state.locks[name] {
+		return nil, ErrLocked
+	}
+	m.state.locks[name] = true
+
+	return func() {
+		m.state.mutex.Lock()
+		defer m.state.mutex.Unlock()
+
+		delete(m.state.locks, name)
+	}, nil
+}
+
+func (m *Memory) StartScrapeRun(ctx context.Context, stage string, parameters map[string]any) (int64, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	id := int64(len(m.state.runs) + 1)
+	m.state.runs = append(m.state.runs, ScrapeRun{Id: id, Stage: stage, StartedAt: time.Now(), Parameters: parameters, GitRevision: GitRevision()})
+	return id, nil
+}
+
+func (m *Memory) FinishScrapeRun(ctx context.Context, id int64, summary RunSummary, runErr error) error {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	run := &m.state.runs[id-1]
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Counts = summary.Counts
+	run.Changed = summary.Changed
+	if runErr != nil {
+		run.Error = runErr.Error()
+	}
+	return nil
+}
+
+func (m *Memory) ListScrapeRuns(ctx context.Context, limit int) ([]ScrapeRun, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	var runs []ScrapeRun
+	for i := len(m.state.runs) - 1; i >= 0 && len(runs) < limit; i-- {
+		runs = append(runs, m.state.runs[i])
+	}
+	return runs, nil
+}
+
+func (m *Memory) InsertSections(ctx context.Context, sections []Section) error {
+	return m.write(func() {
+		for _, section := range sections {
+			m.state.sections[section.Id] = section
+		}
+	})
+}
+
+func (m *Memory) ListSections(ctx context.Context, quarterCode, subjectAreaCode string) ([]Section, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	var sections []Section
+	for _, section := range m.state.sections {
+		if section.QuarterCode == quarterCode && section.SubjectAreaCode == subjectAreaCode {
+			sections = append(sections, section)
+		}
+	}
+	sort.Slice(sections, func(i, j int) bool {
+		if sections[i].CatalogNumber != sections[j].CatalogNumber {
+			return sections[i].CatalogNumber < sections[j].CatalogNumber
+		}
+		return sections[i].Id < sections[j].Id
+	})
+	return sections, nil
+}
+
+func (m *Memory) InsertMeetings(ctx context.Context, sections []Section, meetings []Meeting) error {
+	return m.write(func() {
+		replaced := make(map[QuarterCourse]bool)
+		for _, offering := range sectionOfferings(sections) {
+			replaced[offering] = true
+		}
+		for key := range m.state.meetings {
+			section := m.state.sections[key.sectionId]
+			if replaced[QuarterCourse{QuarterCode: section.QuarterCode, SubjectAreaCode: section.SubjectAreaCode, CatalogNumber: section.CatalogNumber}] {
+				delete(m.state.meetings, key)
+			}
+		}
+		for _, meeting := range meetings {
+			m.state.meetings[meetingKey{meeting.SectionId, meeting.Position}] = meeting
+		}
+	})
+}
+
+func (m *Memory) ListMeetings(ctx context.Context, quarterCode, subjectAreaCode string) ([]Meeting, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	var meetings []Meeting
+	for _, meeting := range m.state.meetings {
+		section := m.state.sections[meeting.SectionId]
+		if section.QuarterCode == quarterCode && section.SubjectAreaCode == subjectAreaCode {
+			meetings = append(meetings, meeting)
+		}
+	}
+	sort.Slice(meetings, func(i, j int) bool {
+		if meetings[i].SectionId != meetings[j].SectionId {
+			return meetings[i].SectionId < meetings[j].SectionId
+		}
+		return meetings[i].Position < meetings[j].Position
+	})
+	return meetings, nil
+}
+
+func (m *Memory) InsertEnrollmentSnapshots(ctx context.Context, snapshots []EnrollmentSnapshot) error {
+	return m.write(func() {
+		for _, snapshot := range snapshots {
+			key := enrollmentSnapshotKey{snapshot.SectionId, snapshot.ObservedAt.UnixNano()}
+			if _, exists := m.state.enrollmentSnapshots[key]; !exists {
+				m.state.enrollmentSnapshots[key] = snapshot
+			}
+		}
+	})
+}
+
+func (m *Memory) ListEnrollmentSnapshots(ctx context.Context, quarterCode, subjectAreaCode string, from, until time.Time) ([]EnrollmentSnapshot, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	var snapshots []EnrollmentSnapshot
+	for _, snapshot := range m.state.enrollmentSnapshots {
+		section := m.state.sections[snapshot.SectionId]
+		if section.QuarterCode != quarterCode || section.SubjectAreaCode != subjectAreaCode {
+			continue
+		}
+		if snapshot.ObservedAt.Before(from) || !snapshot.ObservedAt.Before(until) {
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		if snapshots[i].SectionId != snapshots[j].SectionId {
+			return snapshots[i].SectionId < snapshots[j].SectionId
+		}
+		return snapshots[i].ObservedAt.Before(snapshots[j].ObservedAt)
+	})
+	return snapshots, nil
+}
+
+func (m *Memory) InsertQuarterCourses(ctx context.Context, quarterCourses []QuarterCourse) error {
+	return m.write(func() {
+		for _, quarterCourse := range quarterCourses {
+			m.state.quarterCourses[quarterCourse] = true
+		}
+	})
+}
+
+func (m *Memory) ListCourseOfferings(ctx context.Context, course Course) ([]Quarter, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	var quarters []Quarter
+	for quarterCourse := range m.state.quarterCourses {
+		if quarterCourse.SubjectAreaCode == course.SubjectAreaCode && quarterCourse.CatalogNumber == course.CatalogNumber {
+			quarters = append(quarters, m.state.quarters[quarterCourse.QuarterCode])
+		}
+	}
+	sort.Slice(quarters, func(i, j int) bool {
+		return QuarterRank(quarters[i].Code) < QuarterRank(quarters[j].Code)
+	})
+	return quarters, nil
+}
+
+func (m *Memory) ListQuarterCourses(ctx context.Context, quarter Quarter, subjectArea SubjectArea) ([]Course, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	var courses []Course
+	for quarterCourse := range m.state.quarterCourses {
+		if quarterCourse.QuarterCode == quarter.Code && quarterCourse.SubjectAreaCode == subjectArea.Code {
+			courses = append(courses, Course{SubjectAreaCode: quarterCourse.SubjectAreaCode, CatalogNumber: quarterCourse.CatalogNumber, NodeId: ValueNodeId(quarterCourse.SubjectAreaCode, quarterCourse.CatalogNumber)})
+		}
+	}
+	sort.Slice(courses, func(i, j int) bool {
+		return courses[i].CatalogNumber < courses[j].CatalogNumber
+	})
+	return courses, nil
+}
+
+func (m *Memory) InsertCourseEquivalences(ctx context.Context, courseEquivalences []CourseEquivalence) error {
+	return m.write(func() {
+		for _, e := range courseEquivalences {
+			key := courseKey{e.SubjectAreaCode, e.CatalogNumber}
+			if m.state.courseEquivalences[key] == nil {
+				m.state.courseEquivalences[key] = make(map[courseKey]EquivalenceKind)
+			}
+			m.state.courseEquivalences[key][courseKey{e.EquivalentSubjectAreaCode, e.EquivalentCatalogNumber}] = e.Kind
+		}
+	})
+}
+
+func (m *Memory) ListCourseEquivalents(ctx context.Context, course Course) ([]Course, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	start := courseKey{course.SubjectAreaCode, course.CatalogNumber}
+	reached := map[courseKey]bool{start: true}
+	pending := []courseKey{start}
+	var courses []Course
+	for len(pending) > 0 {
+		key := pending[0]
+		pending = pending[1:]
+		for equivalent := range m.state.courseEquivalences[key] {
+			if reached[equivalent] {
+				continue
+			}
+			reached[equivalent] = true
+			pending = append(pending, equivalent)
+			courses = append(courses, Course{SubjectAreaCode: equivalent.subjectAreaCode, CatalogNumber: equivalent.catalogNumber, NodeId: ValueNodeId(equivalent.subjectAreaCode, equivalent.catalogNumber)})
+		}
+	}
+	sort.Slice(courses, func(i, j int) bool {
+		if courses[i].SubjectAreaCode != courses[j].SubjectAreaCode {
+			return courses[i].SubjectAreaCode < courses[j].SubjectAreaCode
+		}
+		return courses[i].CatalogNumber < courses[j].CatalogNumber
+	})
+	return courses, nil
+}
+
+func (m *Memory) InsertCourseGeCategories(ctx context.Context, courseGeCategories []CourseGeCategory) error {
+	return m.write(func() {
+		for _, courseGeCategory := range courseGeCategories {
+			m.state.courseGeCategories[courseGeCategory] = true
+		}
+	})
+}
+
+func (m *Memory) ListCourseGeCategories(ctx context.Context, quarter Quarter, subjectArea SubjectArea) ([]CourseGeCategory, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	var courseGeCategories []CourseGeCategory
+	for courseGeCategory := range m.state.courseGeCategories {
+		if courseGeCategory.QuarterCode == quarter.Code && courseGeCategory.SubjectAreaCode == subjectArea.Code {
+			courseGeCategories = append(courseGeCategories, courseGeCategory)
+		}
+	}
+	sort.Slice(courseGeCategories, func(i, j int) bool {
+		a, b := courseGeCategories[i], courseGeCategories[j]
+		if a.CatalogNumber != b.CatalogNumber {
+			return a.CatalogNumber < b.CatalogNumber
+		}
+		if a.Foundation != b.Foundation {
+			return a.Foundation < b.Foundation
+		}
+		return a.Category < b.Category
+	})
+	return courseGeCategories, nil
+}
+
+func (m *Memory) ListGeQuarterCourses(ctx context.Context, quarter Quarter, subjectArea SubjectArea, foundation, category string) ([]Course, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	seen := make(map[string]bool)
+	var courses []Course
+	for c := range m.state.courseGeCategories {
+		if c.QuarterCode != quarter.Code || c.SubjectAreaCode != subjectArea.Code || c.Foundation != foundation || (category != "" && c.Category != category) {
+			continue
+		}
+		if seen[c.CatalogNumber] || !m.state.quarterCourses[QuarterCourse{c.QuarterCode, c.SubjectAreaCode, c.CatalogNumber}] {
+			continue
+		}
+		seen[c.CatalogNumber] = true
+		courses = append(courses, Course{SubjectAreaCode: c.SubjectAreaCode, CatalogNumber: c.CatalogNumber, NodeId: ValueNodeId(c.SubjectAreaCode, c.CatalogNumber)})
+	}
+	sort.Slice(courses, func(i, j int) bool {
+		return courses[i].CatalogNumber < courses[j].CatalogNumber
+	})
+	return courses, nil
+}
+
+func (m *Memory) InsertRequisiteObservations(ctx context.Context, observations []RequisiteObservation) error {
+	return m.write(func() {
+		for _, observation := range observations {
+			m.state.requisiteObservations[requisiteObservationKey{observation.CourseNodeId, observation.QuarterCode}] = observation.Relations
+		}
+	})
+}
+
+func (m *Memory) ListRelationsAsOf(ctx context.Context, quarterCode string) ([]Relation, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	rank := QuarterRank(quarterCode)
+	latest := make(map[string]string)
+	for key := range m.state.requisiteObservations {
+		keyRank := QuarterRank(key.quarterCode)
+		if keyRank <= rank && keyRank > QuarterRank(latest[key.courseNodeId]) {
+			latest[key.courseNodeId] = key.quarterCode
+		}
+	}
+
+	seen := make(map[relationKey]bool)
+	var relations []Relation
+	for courseNodeId, latestQuarterCode := range latest {
+		for _, relation := range m.state.requisiteObservations[requisiteObservationKey{courseNodeId, latestQuarterCode}] {
+			if key := relationKeyOf(relation); !seen[key] {
+				seen[key] = true
+				relations = append(relations, relation)
+			}
+		}
+	}
+	sort.Slice(relations, func(i, j int) bool {
+		if relations[i].SourceId != relations[j].SourceId {
+			return relations[i].SourceId < relations[j].SourceId
+		}
+		return relations[i].TargetId < relations[j].TargetId
+	})
+	return relations, nil
+}
+
+func (m *Memory) ListRelationHistory(ctx context.Context, course Course) ([]RelationVersion, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	var quarterCodes []string
+	var observations []relationObservation
+	for key, relations := range m.state.requisiteObservations {
+		if key.courseNodeId != course.NodeId {
+			continue
+		}
+		quarterCodes = append(quarterCodes, key.quarterCode)
+		for _, relation := range relations {
+			observations = append(observations, relationObservation{QuarterCode: key.quarterCode, Relation: relation})
+		}
+	}
+	sort.Slice(quarterCodes, func(i, j int) bool {
+		return QuarterRank(quarterCodes[i]) < QuarterRank(quarterCodes[j])
+	})
+	return relationVersions(course.NodeId, quarterCodes, observations), nil
+}
+
+func (m *Memory) InsertInstructors(ctx context.Context, instructors []Instructor) error {
+	return m.write(func() {
+		for _, instructor := range instructors {
+			if existing, exists := m.state.instructors[instructor.Id]; exists && len(existing.Name) >= len(instructor.Name) {
+				continue
+			}
+			m.state.instructors[instructor.Id] = instructor
+		}
+	})
+}
+
+func (m *Memory) InsertSectionInstructors(ctx context.Context, sectionInstructors []SectionInstructor) error {
+	return m.write(func() {
+		for _, sectionInstructor := range sectionInstructors {
+			m.state.sectionInstructors[sectionInstructor] = true
+		}
+	})
+}
+
+func (m *Memory) listTeachings(match func(section Section, instructor Instructor) bool) []Teaching {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	seen := make(map[Teaching]bool)
+	var teachings []Teaching
+	for sectionInstructor := range m.state.sectionInstructors {
+		section := m.state.sections[sectionInstructor.SectionId]
+		instructor := m.state.instructors[sectionInstructor.InstructorId]
+		if !match(section, instructor) {
+			continue
+		}
+		teaching := Teaching{InstructorId: instructor.Id, InstructorName: instructor.Name, QuarterCode: section.QuarterCode, SubjectAreaCode: section.SubjectAreaCode, CatalogNumber: section.CatalogNumber}
+		if !seen[teaching] {
+			seen[teaching] = true
+			teachings = append(teachings, teaching)
+		}
+	}
+	sort.Slice(teachings, func(i, j int) bool {
+		a, b := teachings[i], teachings[j]
+		if a.QuarterCode != b.QuarterCode {
+			return QuarterRank(a.QuarterCode) < QuarterRank(b.QuarterCode)
+		}
+		if a.SubjectAreaCode != b.SubjectAreaCode {
+			return a.SubjectAreaCode < b.SubjectAreaCode
+		}
+		if a.CatalogNumber != b.CatalogNumber {
+			return a.CatalogNumber < b.CatalogNumber
+		}
+		return a.InstructorName < b.InstructorName
+	})
+	return teachings
+}
+
+func (m *Memory) ListCourseTeachings(ctx context.Context, course Course) ([]Teaching, error) {
+	return m.listTeachings(func(section Section, instructor Instructor) bool {
+		return section.SubjectAreaCode == course.SubjectAreaCode && section.CatalogNumber == course.CatalogNumber
+	}), nil
+}
+
+func (m *Memory) ListInstructorTeachings(ctx context.Context, instructorId string) ([]Teaching, error) {
+	return m.listTeachings(func(section Section, instructor Instructor) bool {
+		return instructor.Id == instructorId
+	}), nil
+}
+
+func (m *Memory) InsertFinalExams(ctx context.Context, finalExams []FinalExam) error {
+	return m.write(func() {
+		for _, finalExam := range finalExams {
+			m.state.finalExams[finalExam.SectionId] = finalExam
+		}
+	})
+}
+
+func (m *Memory) ListFinalExams(ctx context.Context, sectionIds []string) ([]FinalExam, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	var finalExams []FinalExam
+	for _, sectionId := range sectionIds {
+		if finalExam, exists := m.state.finalExams[sectionId]; exists {
+			finalExams = append(finalExams, finalExam)
+		}
+	}
+	return finalExams, nil
+}
+
+func (m *Memory) InsertOfferingDetails(ctx context.Context, offeringsDetails []OfferingDetails) error {
+	return m.write(func() {
+		for _, offeringDetails := range offeringsDetails {
+			m.state.offeringsDetails[QuarterCourse{offeringDetails.QuarterCode, offeringDetails.SubjectAreaCode, offeringDetails.CatalogNumber}] = offeringDetails
+		}
+	})
+}
+
+func (m *Memory) GetOfferingDetails(ctx context.Context, quarterCourse QuarterCourse) (OfferingDetails, bool, error) {
+	m.state.mutex.Lock()
+	defer m.state.mutex.Unlock()
+
+	offeringDetails, exists := m.state.offeringsDetails[quarterCourse]
+	return offeringDetails, exists, nil
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+//go:embed migrations/*.sql migrations/sqlite/*.sql
+var migrations embed.FS
+
+const createSchemaMigrations = `CREATE TABLE IF NOT EXISTS schema_migrations (version text PRIMARY KEY, applied_at timestamptz NOT NULL DEFAULT now())`
+const listSchemaMigrations = `SELECT version FROM schema_migrations`
+const insertSchemaMigration = `INSERT INTO schema_migrations (version) VALUES ($1)`
+
+type Migration struct {
+	Version string
+	Sql     string
+}
+
+func readMigrations(dir string) ([]Migration, error) {
+	paths, err := fs.Glob(migrations, dir+"/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var ms []Migration
+	for _, path := range paths {
+		content, err := migrations.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		version := strings.TrimSuffix(strings.TrimPrefix(path, dir+"/"), ".sql")
+		ms = append(ms, Migration{Version: version, Sql: string(content)})
+	}
+	return ms, nil
+}
+
+// Postgres migrations
+func Migrations() ([]Migration, error) {
+	return readMigrations("migrations")
+}
+
+// SQLite migrations, versioned alongside the Postgres migrations they mirror
+func SQLiteMigrations() ([]Migration, error) {
+	return readMigrations("migrations/sqlite")
+}
+
+// Applies every migration not yet recorded in schema_migrations, each in its own transaction
+func (d *Postgres) Migrate(ctx context.Context) ([]string, error) {
+	if _, err := d.Pool.Exec(ctx, createSchemaMigrations); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.Pool.Query(ctx, listSchemaMigrations)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+	appliedSet := make(map[string]bool)
+	for _, version := range applied {
+		appliedSet[version] = true
+	}
+
+	ms, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, migration := range ms {
+		if appliedSet[migration.Version] {
+			continue
+		}
+
+		err := pgx.BeginFunc(ctx, d.Pool, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, migration.Sql); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, insertSchemaMigration, migration.Version)
+			return err
+		})
+		if err != nil {
+			return versions, err
+		}
+		versions = append(versions, migration.Version)
+	}
+
+	return versions, nil
+}
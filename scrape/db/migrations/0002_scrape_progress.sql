@@ -0,0 +1,15 @@
+-- UNITS NOT SCOPED BY QUARTER OR SUBJECT AREA USE ''
+
+CREATE TYPE scrape_status AS ENUM (
+  'completed', 'failed'
+);
+
+CREATE TABLE scrape_progress (
+  stage text,
+  quarter_code text,
+  subject_area_code text,
+  status scrape_status NOT NULL,
+  error text NOT NULL DEFAULT '',
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (stage, quarter_code, subject_area_code)
+);
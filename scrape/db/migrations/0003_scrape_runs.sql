@@ -0,0 +1,41 @@
+-- FIRST_RUN_ID IS THE RUN THAT FIRST OBSERVED A ROW,
+-- LAST_RUN_ID IS THE RUN THAT LAST OBSERVED IT
+
+CREATE TABLE scrape_runs (
+  id bigserial PRIMARY KEY,
+  stage text NOT NULL,
+  started_at timestamptz NOT NULL DEFAULT now(),
+  finished_at timestamptz,
+  parameters jsonb NOT NULL DEFAULT '{}',
+  git_revision text NOT NULL DEFAULT '',
+  counts jsonb NOT NULL DEFAULT '{}',
+  error text NOT NULL DEFAULT ''
+);
+
+ALTER TABLE quarters
+  ADD COLUMN first_run_id bigint REFERENCES scrape_runs(id),
+  ADD COLUMN last_run_id bigint REFERENCES scrape_runs(id);
+
+ALTER TABLE subject_areas
+  ADD COLUMN first_run_id bigint REFERENCES scrape_runs(id),
+  ADD COLUMN last_run_id bigint REFERENCES scrape_runs(id);
+
+ALTER TABLE quarter_subject_areas
+  ADD COLUMN first_run_id bigint REFERENCES scrape_runs(id),
+  ADD COLUMN last_run_id bigint REFERENCES scrape_runs(id);
+
+ALTER TABLE nodes
+  ADD COLUMN first_run_id bigint REFERENCES scrape_runs(id),
+  ADD COLUMN last_run_id bigint REFERENCES scrape_runs(id);
+
+ALTER TABLE courses
+  ADD COLUMN first_run_id bigint REFERENCES scrape_runs(id),
+  ADD COLUMN last_run_id bigint REFERENCES scrape_runs(id);
+
+ALTER TABLE courses_details
+  ADD COLUMN first_run_id bigint REFERENCES scrape_runs(id),
+  ADD COLUMN last_run_id bigint REFERENCES scrape_runs(id);
+
+ALTER TABLE relations
+  ADD COLUMN first_run_id bigint REFERENCES scrape_runs(id),
+  ADD COLUMN last_run_id bigint REFERENCES scrape_runs(id);
@@ -0,0 +1,33 @@
+-- SECTIONS ARE SCOPED BY QUARTER; DISCUSSIONS AND LABS POINT TO THEIR LECTURE
+
+CREATE TABLE sections (
+  id text PRIMARY KEY,
+  quarter_code text NOT NULL REFERENCES quarters(code),
+  subject_area_code text NOT NULL,
+  catalog_number text NOT NULL,
+  activity text NOT NULL,
+  number text NOT NULL,
+  class_id text NOT NULL,
+  units text NOT NULL,
+  instructors text NOT NULL,
+  parent_id text REFERENCES sections(id),
+  first_run_id bigint REFERENCES scrape_runs(id),
+  last_run_id bigint REFERENCES scrape_runs(id),
+  FOREIGN KEY (subject_area_code, catalog_number) REFERENCES courses(subject_area_code, catalog_number)
+);
+
+CREATE INDEX sections_quarter_subject_area ON sections (quarter_code, subject_area_code);
+
+CREATE TABLE meetings (
+  section_id text REFERENCES sections(id),
+  position integer,
+  days text NOT NULL,
+  start_time text NOT NULL,
+  end_time text NOT NULL,
+  location text NOT NULL,
+  building text NOT NULL,
+  room text NOT NULL,
+  first_run_id bigint REFERENCES scrape_runs(id),
+  last_run_id bigint REFERENCES scrape_runs(id),
+  PRIMARY KEY (section_id, position)
+);
@@ -0,0 +1,13 @@
+-- ENROLLMENT SNAPSHOTS ARE APPENDED ON EVERY SCRAPE, NEVER UPDATED
+
+CREATE TABLE enrollment_snapshots (
+  section_id text REFERENCES sections(id),
+  observed_at timestamptz NOT NULL,
+  status text NOT NULL,
+  enrolled integer NOT NULL,
+  capacity integer NOT NULL,
+  waitlisted integer NOT NULL,
+  waitlist_capacity integer NOT NULL,
+  run_id bigint REFERENCES scrape_runs(id),
+  PRIMARY KEY (section_id, observed_at)
+);
@@ -0,0 +1,14 @@
+-- MULTIPLE-LISTED (M) AND CONCURRENTLY SCHEDULED (C) COURSES, AS READ FROM COURSE DESCRIPTIONS
+-- STORED IN BOTH DIRECTIONS; REQUISITES ON EITHER COURSE ARE SATISFIED BY THE OTHER
+-- NOT TIED TO COURSES, SINCE EITHER COURSE MAY NEVER HAVE BEEN SCRAPED
+
+CREATE TABLE course_equivalences (
+  subject_area_code text,
+  catalog_number text,
+  equivalent_subject_area_code text,
+  equivalent_catalog_number text,
+  kind text NOT NULL,
+  first_run_id bigint REFERENCES scrape_runs(id),
+  last_run_id bigint REFERENCES scrape_runs(id),
+  PRIMARY KEY (subject_area_code, catalog_number, equivalent_subject_area_code, equivalent_catalog_number)
+);
@@ -0,0 +1,3 @@
+-- UNITS WHOSE CONTENT CHANGED IN A RUN, E.G. COURSES WHOSE REQUISITES CHANGED
+
+ALTER TABLE scrape_runs ADD COLUMN changed jsonb NOT NULL DEFAULT '[]';
@@ -0,0 +1 @@
+-- POSTGRES TAKES ADVISORY LOCKS, WHICH NEED NO TABLE; SEE sqlite/0015_scrape_locks.sql
@@ -0,0 +1,16 @@
+-- INVALID QUARTER CODES RAISE A CLEAR ERROR INSTEAD OF POSTGRES'S CASE_NOT_FOUND
+
+CREATE OR REPLACE FUNCTION quarter_rank(code text) RETURNS text AS $$
+  DECLARE
+    year text := LEFT(code, 2);
+  BEGIN
+    CASE RIGHT(code, 1)
+      WHEN 'W' THEN RETURN year || '0';
+      WHEN 'S' THEN RETURN year || '1';
+      WHEN '1' THEN RETURN year || '2';
+      WHEN '2' THEN RETURN year || '3';
+      WHEN 'F' THEN RETURN year || '4';
+      ELSE RAISE EXCEPTION 'Invalid quarter code %', code;
+    END CASE;
+  END;
+$$ LANGUAGE plpgsql;
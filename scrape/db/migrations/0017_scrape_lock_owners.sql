@@ -0,0 +1 @@
+-- POSTGRES ADVISORY LOCKS END WITH THEIR SESSION, SO NEED NO OWNER; SEE sqlite/0017_scrape_lock_owners.sql
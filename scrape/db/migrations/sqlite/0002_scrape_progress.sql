@@ -0,0 +1,11 @@
+-- UNITS NOT SCOPED BY QUARTER OR SUBJECT AREA USE ''
+
+CREATE TABLE scrape_progress (
+  stage text,
+  quarter_code text,
+  subject_area_code text,
+  status text NOT NULL CHECK (status IN ('completed', 'failed')),
+  error text NOT NULL DEFAULT '',
+  updated_at text NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (stage, quarter_code, subject_area_code)
+);
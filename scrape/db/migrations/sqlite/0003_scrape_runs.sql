@@ -0,0 +1,35 @@
+-- FIRST_RUN_ID IS THE RUN THAT FIRST OBSERVED A ROW,
+-- LAST_RUN_ID IS THE RUN THAT LAST OBSERVED IT
+-- SCRAPE RUN TIMESTAMPS ARE RFC 3339 TEXT
+
+CREATE TABLE scrape_runs (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  stage text NOT NULL,
+  started_at text NOT NULL,
+  finished_at text,
+  parameters text NOT NULL DEFAULT '{}',
+  git_revision text NOT NULL DEFAULT '',
+  counts text NOT NULL DEFAULT '{}',
+  error text NOT NULL DEFAULT ''
+);
+
+ALTER TABLE quarters ADD COLUMN first_run_id integer REFERENCES scrape_runs(id);
+ALTER TABLE quarters ADD COLUMN last_run_id integer REFERENCES scrape_runs(id);
+
+ALTER TABLE subject_areas ADD COLUMN first_run_id integer REFERENCES scrape_runs(id);
+ALTER TABLE subject_areas ADD COLUMN last_run_id integer REFERENCES scrape_runs(id);
+
+ALTER TABLE quarter_subject_areas ADD COLUMN first_run_id integer REFERENCES scrape_runs(id);
+ALTER TABLE quarter_subject_areas ADD COLUMN last_run_id integer REFERENCES scrape_runs(id);
+
+ALTER TABLE nodes ADD COLUMN first_run_id integer REFERENCES scrape_runs(id);
+ALTER TABLE nodes ADD COLUMN last_run_id integer REFERENCES scrape_runs(id);
+
+ALTER TABLE courses ADD COLUMN first_run_id integer REFERENCES scrape_runs(id);
+ALTER TABLE courses ADD COLUMN last_run_id integer REFERENCES scrape_runs(id);
+
+ALTER TABLE courses_details ADD COLUMN first_run_id integer REFERENCES scrape_runs(id);
+ALTER TABLE courses_details ADD COLUMN last_run_id integer REFERENCES scrape_runs(id);
+
+ALTER TABLE relations ADD COLUMN first_run_id integer REFERENCES scrape_runs(id);
+ALTER TABLE relations ADD COLUMN last_run_id integer REFERENCES scrape_runs(id);
@@ -0,0 +1,10 @@
+-- HASH OF THE REQUISITE EXPRESSION LAST WRITTEN FOR A COURSE IN A QUARTER
+
+CREATE TABLE content_hashes (
+  quarter_code text REFERENCES quarters(code),
+  subject_area_code text,
+  catalog_number text,
+  hash text NOT NULL,
+  updated_at text NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (quarter_code, subject_area_code, catalog_number)
+);
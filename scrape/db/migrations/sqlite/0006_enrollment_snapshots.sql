@@ -0,0 +1,14 @@
+-- ENROLLMENT SNAPSHOTS ARE APPENDED ON EVERY SCRAPE, NEVER UPDATED
+-- OBSERVED_AT IS FIXED WIDTH RFC 3339 TEXT IN UTC, SO IT ORDERS AS TEXT
+
+CREATE TABLE enrollment_snapshots (
+  section_id text REFERENCES sections(id),
+  observed_at text NOT NULL,
+  status text NOT NULL,
+  enrolled integer NOT NULL,
+  capacity integer NOT NULL,
+  waitlisted integer NOT NULL,
+  waitlist_capacity integer NOT NULL,
+  run_id integer REFERENCES scrape_runs(id),
+  PRIMARY KEY (section_id, observed_at)
+);
@@ -0,0 +1,13 @@
+-- COURSES OFFERED IN EACH QUARTER, AS LISTED BY THE SCHEDULE OF CLASSES
+-- NOT TIED TO COURSES, SINCE A LISTED COURSE MAY FAIL TO SCRAPE
+
+CREATE TABLE quarter_courses (
+  quarter_code text REFERENCES quarters(code),
+  subject_area_code text REFERENCES subject_areas(code),
+  catalog_number text,
+  first_run_id integer REFERENCES scrape_runs(id),
+  last_run_id integer REFERENCES scrape_runs(id),
+  PRIMARY KEY (quarter_code, subject_area_code, catalog_number)
+);
+
+CREATE INDEX quarter_courses_course ON quarter_courses (subject_area_code, catalog_number);
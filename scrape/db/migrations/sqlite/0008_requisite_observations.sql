@@ -0,0 +1,25 @@
+-- THE QUARTERS EACH COURSE'S REQUISITES WERE READ IN, EVEN WHEN IT HAD NONE,
+-- AND THE RELATIONS READ FOR IT IN EACH OF THEM
+
+CREATE TABLE requisite_observations (
+  course_node_id text REFERENCES nodes(id),
+  quarter_code text REFERENCES quarters(code),
+  first_run_id integer REFERENCES scrape_runs(id),
+  last_run_id integer REFERENCES scrape_runs(id),
+  PRIMARY KEY (course_node_id, quarter_code)
+);
+
+CREATE TABLE relation_observations (
+  course_node_id text,
+  quarter_code text,
+  source_id text,
+  target_id text,
+  enforced text,
+  prereq text,
+  coreq text,
+  minimum_grade text,
+  run_id integer REFERENCES scrape_runs(id),
+  PRIMARY KEY (course_node_id, quarter_code, source_id, target_id, enforced, prereq, coreq, minimum_grade),
+  FOREIGN KEY (course_node_id, quarter_code) REFERENCES requisite_observations(course_node_id, quarter_code),
+  FOREIGN KEY (source_id, target_id, enforced, prereq, coreq, minimum_grade) REFERENCES relations(source_id, target_id, enforced, prereq, coreq, minimum_grade)
+);
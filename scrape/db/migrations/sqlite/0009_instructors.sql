@@ -0,0 +1,18 @@
+-- INSTRUCTORS ARE IDENTIFIED BY LAST NAME AND FIRST INITIAL, SO "SMITH, J." AND "SMITH, JOHN" ARE ONE
+
+CREATE TABLE instructors (
+  id text PRIMARY KEY,
+  name text NOT NULL,
+  first_run_id integer REFERENCES scrape_runs(id),
+  last_run_id integer REFERENCES scrape_runs(id)
+);
+
+CREATE TABLE section_instructors (
+  section_id text REFERENCES sections(id),
+  instructor_id text REFERENCES instructors(id),
+  first_run_id integer REFERENCES scrape_runs(id),
+  last_run_id integer REFERENCES scrape_runs(id),
+  PRIMARY KEY (section_id, instructor_id)
+);
+
+CREATE INDEX section_instructors_instructor ON section_instructors (instructor_id);
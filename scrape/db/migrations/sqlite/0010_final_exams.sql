@@ -0,0 +1,13 @@
+-- FINAL EXAMS OF LECTURES, AS LISTED ON THEIR CLASS DETAIL PAGES
+
+CREATE TABLE final_exams (
+  section_id text PRIMARY KEY REFERENCES sections(id),
+  date text NOT NULL,
+  start_time text NOT NULL,
+  end_time text NOT NULL,
+  location text NOT NULL,
+  building text NOT NULL,
+  room text NOT NULL,
+  first_run_id integer REFERENCES scrape_runs(id),
+  last_run_id integer REFERENCES scrape_runs(id)
+);
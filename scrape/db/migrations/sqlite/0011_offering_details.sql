@@ -0,0 +1,27 @@
+-- WHAT CLASS DETAIL PAGES LIST FOR EACH QUARTER'S OFFERING OF A COURSE
+
+CREATE TABLE offering_details (
+  quarter_code text,
+  subject_area_code text,
+  catalog_number text,
+  grading_basis text NOT NULL,
+  grading text NOT NULL,
+  fee_cents integer NOT NULL,
+  impacted integer NOT NULL,
+  notes text NOT NULL,
+  first_run_id integer REFERENCES scrape_runs(id),
+  last_run_id integer REFERENCES scrape_runs(id),
+  PRIMARY KEY (quarter_code, subject_area_code, catalog_number),
+  FOREIGN KEY (quarter_code, subject_area_code, catalog_number) REFERENCES quarter_courses(quarter_code, subject_area_code, catalog_number)
+);
+
+CREATE TABLE offering_restrictions (
+  quarter_code text,
+  subject_area_code text,
+  catalog_number text,
+  position integer,
+  kind text NOT NULL,
+  description text NOT NULL,
+  PRIMARY KEY (quarter_code, subject_area_code, catalog_number, position),
+  FOREIGN KEY (quarter_code, subject_area_code, catalog_number) REFERENCES offering_details(quarter_code, subject_area_code, catalog_number)
+);
@@ -0,0 +1,15 @@
+-- GENERAL EDUCATION FOUNDATIONS AND CATEGORIES OF COURSES OFFERED IN EACH QUARTER
+
+CREATE TABLE course_ge_categories (
+  quarter_code text,
+  subject_area_code text,
+  catalog_number text,
+  foundation text,
+  category text,
+  first_run_id integer REFERENCES scrape_runs(id),
+  last_run_id integer REFERENCES scrape_runs(id),
+  PRIMARY KEY (quarter_code, subject_area_code, catalog_number, foundation, category),
+  FOREIGN KEY (quarter_code, subject_area_code, catalog_number) REFERENCES quarter_courses(quarter_code, subject_area_code, catalog_number)
+);
+
+CREATE INDEX course_ge_categories_category ON course_ge_categories (quarter_code, foundation, category);
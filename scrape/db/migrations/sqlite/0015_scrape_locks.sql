@@ -0,0 +1,7 @@
+-- HELD WHILE A RUN IS IN PROGRESS, SO THAT RUNS OF SEPARATE PROCESSES DO NOT OVERLAP
+-- A PROCESS KILLED MID-RUN LEAVES ITS ROW BEHIND, WHICH HAS TO BE DELETED BY HAND
+
+CREATE TABLE scrape_locks (
+  name text PRIMARY KEY,
+  acquired_at text NOT NULL
+);
@@ -0,0 +1 @@
+-- SQLITE REGISTERS quarter_rank FROM GO, SEE db.QuarterRank; KEPT SO VERSIONS MATCH THE POSTGRES MIGRATIONS
@@ -0,0 +1,6 @@
+-- LOCKS RECORD THE PROCESS HOLDING THEM, WHICH REFRESHES refreshed_at WHILE IT RUNS
+-- A LOCK NOT REFRESHED FOR TEN MINUTES WAS LEFT BEHIND BY A CRASHED PROCESS, SO THE NEXT RUN TAKES IT OVER
+
+ALTER TABLE scrape_locks ADD COLUMN pid integer;
+ALTER TABLE scrape_locks ADD COLUMN refreshed_at text;
+UPDATE scrape_locks SET refreshed_at = acquired_at;
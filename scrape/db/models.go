@@ -1,13 +1,15 @@
 package db
 
+import "time"
+
 type Quarter struct {
-	Code string
-	Name string
+	Code string `json:"code"`
+	Name string `json:"name"`
 }
 
 type SubjectArea struct {
-	Code string
-	Name string
+	Code string `json:"code"`
+	Name string `json:"name"`
 }
 
 type NodeType string
@@ -19,30 +21,233 @@ const (
 )
 
 type Node struct {
-	Id   string
-	Type NodeType
+	Id   string   `json:"id"`
+	Type NodeType `json:"type"`
 }
 
 type Course struct {
-	SubjectAreaCode string
-	CatalogNumber   string
-	NodeId          string
+	SubjectAreaCode string `json:"subject_area_code"`
+	CatalogNumber   string `json:"catalog_number"`
+	NodeId          string `json:"node_id"`
 }
 
 type CourseDetails struct {
+	SubjectAreaCode string `json:"subject_area_code"`
+	CatalogNumber   string `json:"catalog_number"`
+	Name            string `json:"name"`
+	Units           string `json:"units"`
+	Level           string `json:"level"`
+	Description     string `json:"description"`
+}
+
+type Relation struct {
+	SourceId     string  `json:"source_id"`
+	TargetId     string  `json:"target_id"`
+	Enforced     *bool   `json:"enforced"`
+	Prereq       *bool   `json:"prereq"`
+	Coreq        *bool   `json:"coreq"`
+	MinimumGrade *string `json:"minimum_grade"`
+}
+
+type ScrapeStatus string
+
+const (
+	ScrapeStatusCompleted ScrapeStatus = "completed"
+	ScrapeStatusFailed    ScrapeStatus = "failed"
+)
+
+type ScrapeProgress struct {
+	Stage           string
+	QuarterCode     string
+	SubjectAreaCode string
+	Status          ScrapeStatus
+	Error           string
+}
+
+type ContentHash struct {
+	QuarterCode     string
 	SubjectAreaCode string
 	CatalogNumber   string
-	Name            string
-	Units           string
-	Level           string
-	Description     string
+	Hash            string
 }
 
-type Relation struct {
-	SourceId     string
-	TargetId     string
-	Enforced     *bool
-	Prereq       *bool
-	Coreq        *bool
-	MinimumGrade *string
+// A lecture, discussion, lab or other activity of a course in a quarter
+type Section struct {
+	Id              string  `json:"id"`
+	QuarterCode     string  `json:"quarter_code"`
+	SubjectAreaCode string  `json:"subject_area_code"`
+	CatalogNumber   string  `json:"catalog_number"`
+	Activity        string  `json:"activity"` // As abbreviated by the registrar, e.g. Lec, Dis, Lab
+	Number          string  `json:"number"`   // e.g. 1, 1A
+	ClassId         string  `json:"class_id"` // Registrar's id, used in class detail links
+	Units           string  `json:"units"`
+	Instructors     string  `json:"instructors"` // As listed, separated by "; "
+	ParentId        *string `json:"parent_id"`   // Lecture of a discussion or lab
+}
+
+// Days are as listed, e.g. MW, and times are 24-hour HH:MM, empty when not scheduled
+type Meeting struct {
+	SectionId string `json:"section_id"`
+	Position  int    `json:"position"`
+	Days      string `json:"days"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Location  string `json:"location"`
+	Building  string `json:"building"`
+	Room      string `json:"room"`
+}
+
+// A section's seats when observed; status is as listed, e.g. Open, Closed, Waitlist, Cancelled
+type EnrollmentSnapshot struct {
+	SectionId        string    `json:"section_id"`
+	ObservedAt       time.Time `json:"observed_at"`
+	Status           string    `json:"status"`
+	Enrolled         int       `json:"enrolled"`
+	Capacity         int       `json:"capacity"`
+	Waitlisted       int       `json:"waitlisted"`
+	WaitlistCapacity int       `json:"waitlist_capacity"`
+}
+
+// Enrolled over capacity, 0 for sections without capacity
+func (s EnrollmentSnapshot) FillRate() float64 {
+	if s.Capacity == 0 {
+		return 0
+	}
+	return float64(s.Enrolled) / float64(s.Capacity)
+}
+
+type EquivalenceKind string
+
+const (
+	EquivalenceKindMultipleListed EquivalenceKind = "multiple_listed" // Same course in another subject area, prefixed M
+	EquivalenceKindConcurrent     EquivalenceKind = "concurrent"      // Same course at another level, prefixed C
+)
+
+// Courses that are the same offering, so that taking one satisfies requisites on the other
+type CourseEquivalence struct {
+	SubjectAreaCode           string          `json:"subject_area_code"`
+	CatalogNumber             string          `json:"catalog_number"`
+	EquivalentSubjectAreaCode string          `json:"equivalent_subject_area_code"`
+	EquivalentCatalogNumber   string          `json:"equivalent_catalog_number"`
+	Kind                      EquivalenceKind `json:"kind"`
+}
+
+// A course listed by the schedule of classes for a quarter
+type QuarterCourse struct {
+	QuarterCode     string `json:"quarter_code"`
+	SubjectAreaCode string `json:"subject_area_code"`
+	CatalogNumber   string `json:"catalog_number"`
+}
+
+// A General Education category a course counted towards in a quarter, e.g. foundation "SI" and category "LS"
+type CourseGeCategory struct {
+	QuarterCode     string `json:"quarter_code"`
+	SubjectAreaCode string `json:"subject_area_code"`
+	CatalogNumber   string `json:"catalog_number"`
+	Foundation      string `json:"foundation"`
+	Category        string `json:"category"`
+}
+
+// The relations making up a course's requisites as read in a quarter, empty if it had none
+type RequisiteObservation struct {
+	CourseNodeId string     `json:"course_node_id"`
+	QuarterCode  string     `json:"quarter_code"`
+	Relations    []Relation `json:"relations"`
+}
+
+// A relation of a course's requisites, from the first to the last quarter it was read in without interruption
+type RelationVersion struct {
+	Relation
+	CourseNodeId string `json:"course_node_id"`
+	ValidFrom    string `json:"valid_from"`
+	ValidTo      string `json:"valid_to"`
+}
+
+// Name is the most complete listing seen, e.g. "Smith, John" over "Smith, J."
+type Instructor struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type SectionInstructor struct {
+	SectionId    string `json:"section_id"`
+	InstructorId string `json:"instructor_id"`
+}
+
+// An instructor teaching a course in a quarter
+type Teaching struct {
+	InstructorId    string `json:"instructor_id"`
+	InstructorName  string `json:"instructor_name"`
+	QuarterCode     string `json:"quarter_code"`
+	SubjectAreaCode string `json:"subject_area_code"`
+	CatalogNumber   string `json:"catalog_number"`
+}
+
+// Date is YYYY-MM-DD and times are 24-hour HH:MM, empty when not listed
+type FinalExam struct {
+	SectionId string `json:"section_id"`
+	Date      string `json:"date"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Location  string `json:"location"`
+	Building  string `json:"building"`
+	Room      string `json:"room"`
+}
+
+type FinalExamConflict struct {
+	First  FinalExam `json:"first"`
+	Second FinalExam `json:"second"`
+}
+
+// Pairs of exams on the same date whose times overlap; exams without times are assumed not to conflict
+func FindFinalExamConflicts(finalExams []FinalExam) []FinalExamConflict {
+	var conflicts []FinalExamConflict
+	for i, first := range finalExams {
+		for _, second := range finalExams[i+1:] {
+			if first.Date != second.Date || first.StartTime == "" || second.StartTime == "" {
+				continue
+			}
+			// HH:MM times compare correctly as strings
+			if first.StartTime < second.EndTime && second.StartTime < first.EndTime {
+				conflicts = append(conflicts, FinalExamConflict{First: first, Second: second})
+			}
+		}
+	}
+	return conflicts
+}
+
+type GradingBasis string
+
+const (
+	GradingBasisLetter       GradingBasis = "letter"
+	GradingBasisPassNoPass   GradingBasis = "pass_no_pass"
+	GradingBasisLetterOrPass GradingBasis = "letter_or_pass_no_pass"
+	GradingBasisSatisfactory GradingBasis = "satisfactory_unsatisfactory"
+	GradingBasisUnknown      GradingBasis = ""
+)
+
+// What a course's class detail page lists for one quarter's offering
+type OfferingDetails struct {
+	QuarterCode     string        `json:"quarter_code"`
+	SubjectAreaCode string        `json:"subject_area_code"`
+	CatalogNumber   string        `json:"catalog_number"`
+	GradingBasis    GradingBasis  `json:"grading_basis"`
+	Grading         string        `json:"grading"` // As listed
+	FeeCents        int           `json:"fee_cents"`
+	Impacted        bool          `json:"impacted"`
+	Notes           string        `json:"notes"`
+	Restrictions    []Restriction `json:"restrictions"`
+}
+
+type RestrictionKind string
+
+const (
+	RestrictionKindMajor         RestrictionKind = "major"
+	RestrictionKindClassStanding RestrictionKind = "class_standing"
+	RestrictionKindOther         RestrictionKind = "other"
+)
+
+type Restriction struct {
+	Kind        RestrictionKind `json:"kind"`
+	Description string          `json:"description"`
 }
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const insertOfferingDetails = `INSERT INTO offering_details (quarter_code, subject_area_code, catalog_number, grading_basis, grading, fee_cents, impacted, notes, first_run_id, last_run_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9) ON CONFLICT (quarter_code, subject_area_code, catalog_number) DO UPDATE SET grading_basis=EXCLUDED.grading_basis, grading=EXCLUDED.grading, fee_cents=EXCLUDED.fee_cents, impacted=EXCLUDED.impacted, notes=EXCLUDED.notes, last_run_id=COALESCE(EXCLUDED.last_run_id, offering_details.last_run_id)`
+const deleteOfferingRestrictions = `DELETE FROM offering_restrictions WHERE quarter_code = $1 AND subject_area_code = $2 AND catalog_number = $3`
+const insertOfferingRestriction = `INSERT INTO offering_restrictions (quarter_code, subject_area_code, catalog_number, position, kind, description) VALUES ($1, $2, $3, $4, $5, $6)`
+const getOfferingDetails = `SELECT quarter_code, subject_area_code, catalog_number, grading_basis, grading, fee_cents, impacted, notes FROM offering_details WHERE quarter_code = $1 AND subject_area_code = $2 AND catalog_number = $3`
+const listOfferingRestrictions = `SELECT kind, description FROM offering_restrictions WHERE quarter_code = $1 AND subject_area_code = $2 AND catalog_number = $3 ORDER BY position`
+
+func offeringDetailsArgs(offeringDetails OfferingDetails, runId *int64) []any {
+	return []any{offeringDetails.QuarterCode, offeringDetails.SubjectAreaCode, offeringDetails.CatalogNumber, string(offeringDetails.GradingBasis), offeringDetails.Grading, offeringDetails.FeeCents, offeringDetails.Impacted, offeringDetails.Notes, runId}
+}
+
+// Replaces the restrictions stored for the same offering
+func (d *Postgres) InsertOfferingDetails(ctx context.Context, offeringsDetails []OfferingDetails) error {
+	if len(offeringsDetails) == 0 {
+		return nil
+	}
+
+	batch := pgx.Batch{}
+	var queuedQueries []*pgx.QueuedQuery
+
+	for _, offeringDetails := range offeringsDetails {
+		key := []any{offeringDetails.QuarterCode, offeringDetails.SubjectAreaCode, offeringDetails.CatalogNumber}
+		queuedQueries = append(queuedQueries, batch.Queue(insertOfferingDetails, offeringDetailsArgs(offeringDetails, d.RunId)...))
+		queuedQueries = append(queuedQueries, batch.Queue(deleteOfferingRestrictions, key...))
+		for position, restriction := range offeringDetails.Restrictions {
+			queuedQueries = append(queuedQueries, batch.Queue(insertOfferingRestriction, append(key, position, string(restriction.Kind), restriction.Description)...))
+		}
+	}
+
+	for _, queuedQuery := range queuedQueries {
+		queuedQuery.Exec(insertCallback)
+	}
+
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Returns false if the offering's class detail page was not scraped
+func (d *Postgres) GetOfferingDetails(ctx context.Context, quarterCourse QuarterCourse) (OfferingDetails, bool, error) {
+	key := []any{quarterCourse.QuarterCode, quarterCourse.SubjectAreaCode, quarterCourse.CatalogNumber}
+
+	var offeringDetails OfferingDetails
+	var gradingBasis string
+	err := d.conn().QueryRow(ctx, getOfferingDetails, key...).Scan(&offeringDetails.QuarterCode, &offeringDetails.SubjectAreaCode, &offeringDetails.CatalogNumber, &gradingBasis, &offeringDetails.Grading, &offeringDetails.FeeCents, &offeringDetails.Impacted, &offeringDetails.Notes)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return OfferingDetails{}, false, nil
+	}
+	if err != nil {
+		return OfferingDetails{}, false, err
+	}
+	offeringDetails.GradingBasis = GradingBasis(gradingBasis)
+
+	rows, err := d.conn().Query(ctx, listOfferingRestrictions, key...)
+	if err != nil {
+		return OfferingDetails{}, false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var restriction Restriction
+		var kind string
+		if err := rows.Scan(&kind, &restriction.Description); err != nil {
+			return OfferingDetails{}, false, err
+		}
+		restriction.Kind = RestrictionKind(kind)
+		offeringDetails.Restrictions = append(offeringDetails.Restrictions, restriction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return OfferingDetails{}, false, err
+	}
+
+	return offeringDetails, true, nil
+}
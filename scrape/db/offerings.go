@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const insertQuarterCourse = `INSERT INTO quarter_courses (quarter_code, subject_area_code, catalog_number, first_run_id, last_run_id) VALUES ($1, $2, $3, $4, $4) ON CONFLICT (quarter_code, subject_area_code, catalog_number) DO UPDATE SET last_run_id=COALESCE(EXCLUDED.last_run_id, quarter_courses.last_run_id)`
+const listCourseOfferings = `SELECT quarters.code, quarters.name FROM quarter_courses JOIN quarters ON quarter_courses.quarter_code = quarters.code WHERE quarter_courses.subject_area_code = $1 AND quarter_courses.catalog_number = $2 ORDER BY quarter_rank(quarters.code)`
+const listQuarterCourses = `SELECT subject_area_code, catalog_number FROM quarter_courses WHERE quarter_code = $1 AND subject_area_code = $2 ORDER BY catalog_number`
+
+func (d *Postgres) InsertQuarterCourses(ctx context.Context, quarterCourses []QuarterCourse) error {
+	if len(quarterCourses) == 0 {
+		return nil
+	}
+
+	batch := pgx.Batch{}
+	var queuedQueries []*pgx.QueuedQuery
+
+	for _, quarterCourse := range quarterCourses {
+		queuedQueries = append(queuedQueries, batch.Queue(insertQuarterCourse, quarterCourse.QuarterCode, quarterCourse.SubjectAreaCode, quarterCourse.CatalogNumber, d.RunId))
+	}
+
+	for _, queuedQuery := range queuedQueries {
+		queuedQuery.Exec(insertCallback)
+	}
+
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Postgres) ListCourseOfferings(ctx context.Context, course Course) ([]Quarter, error) {
+	sql := listCourseOfferings
+	rows, err := d.conn().Query(ctx, sql, course.SubjectAreaCode, course.CatalogNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var quarters []Quarter
+	for rows.Next() {
+		var quarter Quarter
+		if err := rows.Scan(&quarter.Code, &quarter.Name); err != nil {
+			return nil, err
+		}
+		quarters = append(quarters, quarter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return quarters, nil
+}
+
+// Node ids are derived from the course, since offered courses need not have been scraped
+func (d *Postgres) ListQuarterCourses(ctx context.Context, quarter Quarter, subjectArea SubjectArea) ([]Course, error) {
+	sql := listQuarterCourses
+	rows, err := d.conn().Query(ctx, sql, quarter.Code, subjectArea.Code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var courses []Course
+	for rows.Next() {
+		var course Course
+		if err := rows.Scan(&course.SubjectAreaCode, &course.CatalogNumber); err != nil {
+			return nil, err
+		}
+		course.NodeId = ValueNodeId(course.SubjectAreaCode, course.CatalogNumber)
+		courses = append(courses, course)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return courses, nil
+}
@@ -0,0 +1,236 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+)
+
+// Receives a database's writes as JSON Lines, one {"type": ..., "value": ...} object per written row
+type Output struct {
+	mutex  sync.Mutex
+	writer *bufio.Writer
+	closer io.Closer
+
+	// Set on outputs buffering a transaction
+	parent *Output
+	buffer bytes.Buffer
+}
+
+type record struct {
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+type quarterSubjectArea struct {
+	QuarterCode     string `json:"quarter_code"`
+	SubjectAreaCode string `json:"subject_area_code"`
+}
+
+func NewOutput(w io.Writer) *Output {
+	return &Output{writer: bufio.NewWriter(w)}
+}
+
+// An empty path or "-" is stdout
+func OpenOutput(path string) (*Output, error) {
+	if path == "" || path == "-" {
+		return NewOutput(os.Stdout), nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	output := NewOutput(file)
+	output.closer = file
+	return output, nil
+}
+
+func writeRecords[T any](o *Output, recordType string, values []T) error {
+	var lines bytes.Buffer
+	encoder := json.NewEncoder(&lines)
+	for _, value := range values {
+		if err := encoder.Encode(record{Type: recordType, Value: value}); err != nil {
+			return err
+		}
+	}
+	return o.write(lines.Bytes())
+}
+
+func (o *Output) write(lines []byte) error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if o.parent != nil {
+		o.buffer.Write(lines)
+		return nil
+	}
+	_, err := o.writer.Write(lines)
+	return err
+}
+
+// Lines written to the returned output reach o only once committed
+func (o *Output) begin() *Output {
+	return &Output{parent: o}
+}
+
+func (o *Output) commit() error {
+	return o.parent.write(o.buffer.Bytes())
+}
+
+func (o *Output) Close() error {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	if err := o.writer.Flush(); err != nil {
+		return err
+	}
+	if o.closer != nil {
+		return o.closer.Close()
+	}
+	return nil
+}
+
+// Store writing scraped rows to Output instead of the underlying store, which is still read from.
+// Bookkeeping writes, such as progress, hashes and scrape runs, are dropped.
+// Only reads are embedded, so every write method of Store has to be implemented here.
+type DryRun struct {
+	Reader
+	Output *Output
+}
+
+var _ Store = (*DryRun)(nil)
+
+func (d *DryRun) InsertQuarters(ctx context.Context, quarters []Quarter) error {
+	return writeRecords(d.Output, "quarter", quarters)
+}
+
+func (d *DryRun) InsertSubjectAreas(ctx context.Context, subjectAreas []SubjectArea) error {
+	return writeRecords(d.Output, "subject_area", subjectAreas)
+}
+
+func (d *DryRun) InsertQuarterSubjectAreas(ctx context.Context, quarter Quarter, subjectAreas []SubjectArea) error {
+	var quarterSubjectAreas []quarterSubjectArea
+	for _, subjectArea := range subjectAreas {
+		quarterSubjectAreas = append(quarterSubjectAreas, quarterSubjectArea{QuarterCode: quarter.Code, SubjectAreaCode: subjectArea.Code})
+	}
+	return writeRecords(d.Output, "quarter_subject_area", quarterSubjectAreas)
+}
+
+func (d *DryRun) InsertNodes(ctx context.Context, nodes []Node) error {
+	return writeRecords(d.Output, "node", nodes)
+}
+
+func (d *DryRun) InsertCourses(ctx context.Context, courses []Course) error {
+	return writeRecords(d.Output, "course", courses)
+}
+
+func (d *DryRun) InsertRelations(ctx context.Context, relations []Relation) error {
+	return writeRecords(d.Output, "relation", relations)
+}
+
+func (d *DryRun) InsertRequisiteObservations(ctx context.Context, observations []RequisiteObservation) error {
+	return writeRecords(d.Output, "requisite_observation", observations)
+}
+
+func (d *DryRun) InsertCoursesDetails(ctx context.Context, coursesDetails []CourseDetails) error {
+	return writeRecords(d.Output, "course_details", coursesDetails)
+}
+
+func (d *DryRun) InsertSections(ctx context.Context, sections []Section) error {
+	return writeRecords(d.Output, "section", sections)
+}
+
+func (d *DryRun) InsertMeetings(ctx context.Context, sections []Section, meetings []Meeting) error {
+	return writeRecords(d.Output, "meeting", meetings)
+}
+
+func (d *DryRun) InsertInstructors(ctx context.Context, instructors []Instructor) error {
+	return writeRecords(d.Output, "instructor", instructors)
+}
+
+func (d *DryRun) InsertSectionInstructors(ctx context.Context, sectionInstructors []SectionInstructor) error {
+	return writeRecords(d.Output, "section_instructor", sectionInstructors)
+}
+
+func (d *DryRun) InsertFinalExams(ctx context.Context, finalExams []FinalExam) error {
+	return writeRecords(d.Output, "final_exam", finalExams)
+}
+
+func (d *DryRun) InsertOfferingDetails(ctx context.Context, offeringsDetails []OfferingDetails) error {
+	return writeRecords(d.Output, "offering_details", offeringsDetails)
+}
+
+func (d *DryRun) InsertQuarterCourses(ctx context.Context, quarterCourses []QuarterCourse) error {
+	return writeRecords(d.Output, "quarter_course", quarterCourses)
+}
+
+func (d *DryRun) InsertCourseEquivalences(ctx context.Context, courseEquivalences []CourseEquivalence) error {
+	return writeRecords(d.Output, "course_equivalence", courseEquivalences)
+}
+
+func (d *DryRun) InsertCourseGeCategories(ctx context.Context, courseGeCategories []CourseGeCategory) error {
+	return writeRecords(d.Output, "course_ge_category", courseGeCategories)
+}
+
+func (d *DryRun) InsertEnrollmentSnapshots(ctx context.Context, snapshots []EnrollmentSnapshot) error {
+	return writeRecords(d.Output, "enrollment_snapshot", snapshots)
+}
+
+func (d *DryRun) ClearScrapeProgress(ctx context.Context, progress []ScrapeProgress) error {
+	return nil
+}
+
+func (d *DryRun) UpsertScrapeProgress(ctx context.Context, progress ScrapeProgress) error {
+	return nil
+}
+
+func (d *DryRun) UpsertContentHashes(ctx context.Context, contentHashes []ContentHash) error {
+	return nil
+}
+
+// Dry runs write nothing, so they need no lock
+func (d *DryRun) TryLock(ctx context.Context, name string) (func(), error) {
+	return func() {}, nil
+}
+
+func (d *DryRun) StartScrapeRun(ctx context.Context, stage string, parameters map[string]any) (int64, error) {
+	return 0, nil
+}
+
+func (d *DryRun) FinishScrapeRun(ctx context.Context, id int64, summary RunSummary, runErr error) error {
+	return nil
+}
+
+func (d *DryRun) WithRun(runId int64) Store {
+	return d
+}
+
+// The transaction's rows are emitted together, and only if it would have committed
+func (d *DryRun) InTx(ctx context.Context, fn func(tx Store) error) error {
+	tx := &DryRun{Reader: d.Reader, Output: d.Output.begin()}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return tx.Output.commit()
+}
+
+func (d *DryRun) Migrate(ctx context.Context) ([]string, error) {
+	return nil, errors.New("Migrations cannot be dry run")
+}
+
+func (d *DryRun) Close() error {
+	outputErr := d.Output.Close()
+	if err := d.Reader.Close(); err != nil {
+		return err
+	}
+	return outputErr
+}
@@ -10,19 +10,26 @@ import (
 )
 
 const listQuarters = `SELECT code, name FROM quarters ORDER BY quarter_rank(code)`
-const insertQuarter = `INSERT INTO quarters (code, name) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+const insertQuarter = `INSERT INTO quarters (code, name, first_run_id, last_run_id) VALUES ($1, $2, $3, $3) ON CONFLICT (code) DO UPDATE SET last_run_id=COALESCE(EXCLUDED.last_run_id, quarters.last_run_id)`
 
 const listSubjectAreas = `SELECT code, name FROM subject_areas ORDER BY code`
-const insertSubjectArea = `INSERT INTO subject_areas (code, name) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+const insertSubjectArea = `INSERT INTO subject_areas (code, name, first_run_id, last_run_id) VALUES ($1, $2, $3, $3) ON CONFLICT (code) DO UPDATE SET last_run_id=COALESCE(EXCLUDED.last_run_id, subject_areas.last_run_id)`
 
 const listQuarterSubjectAreas = `SELECT subject_areas.code, subject_areas.name FROM quarter_subject_areas JOIN subject_areas ON quarter_subject_areas.subject_area_code = subject_areas.code WHERE quarter_code = $1 ORDER BY subject_areas.code`
-const insertQuarterSubjectArea = `INSERT INTO quarter_subject_areas (quarter_code, subject_area_code) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+const insertQuarterSubjectArea = `INSERT INTO quarter_subject_areas (quarter_code, subject_area_code, first_run_id, last_run_id) VALUES ($1, $2, $3, $3) ON CONFLICT (quarter_code, subject_area_code) DO UPDATE SET last_run_id=COALESCE(EXCLUDED.last_run_id, quarter_subject_areas.last_run_id)`
 
-const insertNode = `INSERT INTO nodes (id, type) VALUES ($1, $2) ON CONFLICT id DO UPDATE type=EXCLUDED.type`
-const insertCourse = `INSERT INTO courses (subject_area_code, catalog_number, node_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
-const insertRelation = `INSERT INTO relations (source_id, target_id, enforced, prereq, coreq, minimum_grade) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT DO NOTHING`
+const insertNode = `INSERT INTO nodes (id, type, first_run_id, last_run_id) VALUES ($1, $2, $3, $3) ON CONFLICT (id) DO UPDATE SET type=EXCLUDED.type, last_run_id=COALESCE(EXCLUDED.last_run_id, nodes.last_run_id)`
+const insertCourse = `INSERT INTO courses (subject_area_code, catalog_number, node_id, first_run_id, last_run_id) VALUES ($1, $2, $3, $4, $4) ON CONFLICT (subject_area_code, catalog_number) DO UPDATE SET last_run_id=COALESCE(EXCLUDED.last_run_id, courses.last_run_id)`
+const insertRelation = `INSERT INTO relations (source_id, target_id, enforced, prereq, coreq, minimum_grade, first_run_id, last_run_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $7) ON CONFLICT (source_id, target_id, enforced, prereq, coreq, minimum_grade) DO UPDATE SET last_run_id=COALESCE(EXCLUDED.last_run_id, relations.last_run_id)`
 
-const insertCourseDetails = `INSERT INTO courses_details (subject_area_code, catalog_number, name, units, level, description) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (subject_area_code, catalog_number) DO UPDATE SET name=EXCLUDED.name, units=EXCLUDED.units, level=EXCLUDED.level, description=EXCLUDED.description`
+const insertCourseDetails = `INSERT INTO courses_details (subject_area_code, catalog_number, name, units, level, description, first_run_id, last_run_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $7) ON CONFLICT (subject_area_code, catalog_number) DO UPDATE SET name=EXCLUDED.name, units=EXCLUDED.units, level=EXCLUDED.level, description=EXCLUDED.description, last_run_id=COALESCE(EXCLUDED.last_run_id, courses_details.last_run_id)`
+
+const listScrapeProgress = `SELECT stage, quarter_code, subject_area_code, status, error FROM scrape_progress WHERE stage = $1`
+const clearScrapeProgress = `DELETE FROM scrape_progress WHERE stage = $1 AND quarter_code = $2 AND subject_area_code = $3`
+const upsertScrapeProgress = `INSERT INTO scrape_progress (stage, quarter_code, subject_area_code, status, error) VALUES ($1, $2, $3, $4, $5) ON CONFLICT (stage, quarter_code, subject_area_code) DO UPDATE SET status=EXCLUDED.status, error=EXCLUDED.error, updated_at=now()`
+
+const listContentHashes = `SELECT quarter_code, subject_area_code, catalog_number, hash FROM content_hashes WHERE quarter_code = $1 AND subject_area_code = $2`
+const upsertContentHash = `INSERT INTO content_hashes (quarter_code, subject_area_code, catalog_number, hash) VALUES ($1, $2, $3, $4) ON CONFLICT (quarter_code, subject_area_code, catalog_number) DO UPDATE SET hash=EXCLUDED.hash, updated_at=now()`
 
 func FormatOptionalBoolean(b *bool) string {
 	if b != nil {
@@ -38,13 +45,30 @@ func FormatOptionalString(s *string) string {
 	return ""
 }
 
+// Inverse of FormatOptionalBoolean
+func ParseOptionalBoolean(s string) *bool {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return nil
+	}
+	return &b
+}
+
+// Inverse of FormatOptionalString
+func ParseOptionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
 func insertCallback(ct pgconn.CommandTag) error {
 	return nil
 }
 
-func (d *Database) ListQuarters() ([]Quarter, error) {
+func (d *Postgres) ListQuarters(ctx context.Context) ([]Quarter, error) {
 	sql := listQuarters
-	rows, err := d.Pool.Query(context.Background(), sql)
+	rows, err := d.conn().Query(ctx, sql)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +90,7 @@ func (d *Database) ListQuarters() ([]Quarter, error) {
 	return quarters, nil
 }
 
-func (d *Database) InsertQuarters(quarters []Quarter) error {
+func (d *Postgres) InsertQuarters(ctx context.Context, quarters []Quarter) error {
 	if len(quarters) == 0 {
 		return nil
 	}
@@ -75,23 +99,23 @@ func (d *Database) InsertQuarters(quarters []Quarter) error {
 	var queuedQueries []*pgx.QueuedQuery
 
 	for _, quarter := range quarters {
-		queuedQueries = append(queuedQueries, batch.Queue(insertQuarter, quarter.Code, quarter.Name))
+		queuedQueries = append(queuedQueries, batch.Queue(insertQuarter, quarter.Code, quarter.Name, d.RunId))
 	}
 
 	for _, queuedQuery := range queuedQueries {
 		queuedQuery.Exec(insertCallback)
 	}
 
-	if err := d.Pool.SendBatch(context.Background(), &batch).Close(); err != nil {
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *Database) ListSubjectAreas() ([]SubjectArea, error) {
+func (d *Postgres) ListSubjectAreas(ctx context.Context) ([]SubjectArea, error) {
 	sql := listSubjectAreas
-	rows, err := d.Pool.Query(context.Background(), sql)
+	rows, err := d.conn().Query(ctx, sql)
 	if err != nil {
 		return nil, err
 	}
@@ -113,7 +137,7 @@ func (d *Database) ListSubjectAreas() ([]SubjectArea, error) {
 	return subjectAreas, nil
 }
 
-func (d *Database) InsertSubjectAreas(subjectAreas []SubjectArea) error {
+func (d *Postgres) InsertSubjectAreas(ctx context.Context, subjectAreas []SubjectArea) error {
 	if len(subjectAreas) == 0 {
 		return nil
 	}
@@ -122,23 +146,23 @@ func (d *Database) InsertSubjectAreas(subjectAreas []SubjectArea) error {
 	var queuedQueries []*pgx.QueuedQuery
 
 	for _, subjectArea := range subjectAreas {
-		queuedQueries = append(queuedQueries, batch.Queue(insertSubjectArea, subjectArea.Code, subjectArea.Name))
+		queuedQueries = append(queuedQueries, batch.Queue(insertSubjectArea, subjectArea.Code, subjectArea.Name, d.RunId))
 	}
 
 	for _, queuedQuery := range queuedQueries {
 		queuedQuery.Exec(insertCallback)
 	}
 
-	if err := d.Pool.SendBatch(context.Background(), &batch).Close(); err != nil {
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *Database) ListQuarterSubjectAreas(quarter Quarter) ([]SubjectArea, error) {
+func (d *Postgres) ListQuarterSubjectAreas(ctx context.Context, quarter Quarter) ([]SubjectArea, error) {
 	sql := listQuarterSubjectAreas
-	rows, err := d.Pool.Query(context.Background(), sql, quarter.Code)
+	rows, err := d.conn().Query(ctx, sql, quarter.Code)
 	if err != nil {
 		return nil, err
 	}
@@ -160,7 +184,7 @@ func (d *Database) ListQuarterSubjectAreas(quarter Quarter) ([]SubjectArea, erro
 	return subjectAreas, nil
 }
 
-func (d *Database) InsertQuarterSubjectAreas(quarter Quarter, subjectAreas []SubjectArea) error {
+func (d *Postgres) InsertQuarterSubjectAreas(ctx context.Context, quarter Quarter, subjectAreas []SubjectArea) error {
 	if len(subjectAreas) == 0 {
 		return nil
 	}
@@ -169,21 +193,21 @@ func (d *Database) InsertQuarterSubjectAreas(quarter Quarter, subjectAreas []Sub
 	var queuedQueries []*pgx.QueuedQuery
 
 	for _, subjectArea := range subjectAreas {
-		queuedQueries = append(queuedQueries, batch.Queue(insertQuarterSubjectArea, quarter.Code, subjectArea.Code))
+		queuedQueries = append(queuedQueries, batch.Queue(insertQuarterSubjectArea, quarter.Code, subjectArea.Code, d.RunId))
 	}
 
 	for _, queuedQuery := range queuedQueries {
 		queuedQuery.Exec(insertCallback)
 	}
 
-	if err := d.Pool.SendBatch(context.Background(), &batch).Close(); err != nil {
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *Database) InsertNodes(nodes []Node) error {
+func (d *Postgres) InsertNodes(ctx context.Context, nodes []Node) error {
 	if len(nodes) == 0 {
 		return nil
 	}
@@ -192,21 +216,21 @@ func (d *Database) InsertNodes(nodes []Node) error {
 	var queuedQueries []*pgx.QueuedQuery
 
 	for _, node := range nodes {
-		queuedQueries = append(queuedQueries, batch.Queue(insertNode, node.Id, node.Type))
+		queuedQueries = append(queuedQueries, batch.Queue(insertNode, node.Id, node.Type, d.RunId))
 	}
 
 	for _, queuedQuery := range queuedQueries {
 		queuedQuery.Exec(insertCallback)
 	}
 
-	if err := d.Pool.SendBatch(context.Background(), &batch).Close(); err != nil {
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *Database) InsertCourses(courses []Course) error {
+func (d *Postgres) InsertCourses(ctx context.Context, courses []Course) error {
 	if len(courses) == 0 {
 		return nil
 	}
@@ -215,21 +239,21 @@ func (d *Database) InsertCourses(courses []Course) error {
 	var queuedQueries []*pgx.QueuedQuery
 
 	for _, course := range courses {
-		queuedQueries = append(queuedQueries, batch.Queue(insertCourse, course.SubjectAreaCode, course.CatalogNumber, course.NodeId))
+		queuedQueries = append(queuedQueries, batch.Queue(insertCourse, course.SubjectAreaCode, course.CatalogNumber, course.NodeId, d.RunId))
 	}
 
 	for _, queuedQuery := range queuedQueries {
 		queuedQuery.Exec(insertCallback)
 	}
 
-	if err := d.Pool.SendBatch(context.Background(), &batch).Close(); err != nil {
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *Database) InsertRelations(relations []Relation) error {
+func (d *Postgres) InsertRelations(ctx context.Context, relations []Relation) error {
 	if len(relations) == 0 {
 		return nil
 	}
@@ -243,21 +267,21 @@ func (d *Database) InsertRelations(relations []Relation) error {
 		coreq := FormatOptionalBoolean(relation.Coreq)
 		minimumGrade := FormatOptionalString(relation.MinimumGrade)
 
-		queuedQueries = append(queuedQueries, batch.Queue(insertRelation, relation.SourceId, relation.TargetId, enforced, prereq, coreq, minimumGrade))
+		queuedQueries = append(queuedQueries, batch.Queue(insertRelation, relation.SourceId, relation.TargetId, enforced, prereq, coreq, minimumGrade, d.RunId))
 	}
 
 	for _, queuedQuery := range queuedQueries {
 		queuedQuery.Exec(insertCallback)
 	}
 
-	if err := d.Pool.SendBatch(context.Background(), &batch).Close(); err != nil {
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (d *Database) InsertCoursesDetails(coursesDetails []CourseDetails) error {
+func (d *Postgres) InsertCoursesDetails(ctx context.Context, coursesDetails []CourseDetails) error {
 	if len(coursesDetails) == 0 {
 		return nil
 	}
@@ -276,6 +300,7 @@ func (d *Database) InsertCoursesDetails(coursesDetails []CourseDetails) error {
 				courseDetails.Units,
 				courseDetails.Level,
 				strings.ReplaceAll(courseDetails.Description, "\x00", ""),
+				d.RunId,
 			),
 		)
 	}
@@ -284,7 +309,104 @@ func (d *Database) InsertCoursesDetails(coursesDetails []CourseDetails) error {
 		queuedQuery.Exec(insertCallback)
 	}
 
-	if err := d.Pool.SendBatch(context.Background(), &batch).Close(); err != nil {
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Postgres) ListScrapeProgress(ctx context.Context, stage string) ([]ScrapeProgress, error) {
+	sql := listScrapeProgress
+	rows, err := d.conn().Query(ctx, sql, stage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var progress []ScrapeProgress
+	for rows.Next() {
+		var p ScrapeProgress
+		if err := rows.Scan(&p.Stage, &p.QuarterCode, &p.SubjectAreaCode, &p.Status, &p.Error); err != nil {
+			return nil, err
+		}
+		progress = append(progress, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return progress, nil
+}
+
+func (d *Postgres) ClearScrapeProgress(ctx context.Context, progress []ScrapeProgress) error {
+	if len(progress) == 0 {
+		return nil
+	}
+	batch := pgx.Batch{}
+	var queuedQueries []*pgx.QueuedQuery
+
+	for _, p := range progress {
+		queuedQueries = append(queuedQueries, batch.Queue(clearScrapeProgress, p.Stage, p.QuarterCode, p.SubjectAreaCode))
+	}
+
+	for _, queuedQuery := range queuedQueries {
+		queuedQuery.Exec(insertCallback)
+	}
+
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Postgres) UpsertScrapeProgress(ctx context.Context, progress ScrapeProgress) error {
+	_, err := d.conn().Exec(ctx, upsertScrapeProgress, progress.Stage, progress.QuarterCode, progress.SubjectAreaCode, progress.Status, progress.Error)
+	return err
+}
+
+func (d *Postgres) ListContentHashes(ctx context.Context, quarterCode, subjectAreaCode string) ([]ContentHash, error) {
+	sql := listContentHashes
+	rows, err := d.conn().Query(ctx, sql, quarterCode, subjectAreaCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contentHashes []ContentHash
+	for rows.Next() {
+		var contentHash ContentHash
+		if err := rows.Scan(&contentHash.QuarterCode, &contentHash.SubjectAreaCode, &contentHash.CatalogNumber, &contentHash.Hash); err != nil {
+			return nil, err
+		}
+		contentHashes = append(contentHashes, contentHash)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return contentHashes, nil
+}
+
+func (d *Postgres) UpsertContentHashes(ctx context.Context, contentHashes []ContentHash) error {
+	if len(contentHashes) == 0 {
+		return nil
+	}
+	batch := pgx.Batch{}
+	var queuedQueries []*pgx.QueuedQuery
+
+	for _, contentHash := range contentHashes {
+		queuedQueries = append(queuedQueries, batch.Queue(upsertContentHash, contentHash.QuarterCode, contentHash.SubjectAreaCode, contentHash.CatalogNumber, contentHash.Hash))
+	}
+
+	for _, queuedQuery := range queuedQueries {
+		queuedQuery.Exec(insertCallback)
+	}
+
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
 		return err
 	}
 
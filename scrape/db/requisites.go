@@ -0,0 +1,166 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const insertRequisiteObservation = `INSERT INTO requisite_observations (course_node_id, quarter_code, first_run_id, last_run_id) VALUES ($1, $2, $3, $3) ON CONFLICT (course_node_id, quarter_code) DO UPDATE SET last_run_id=COALESCE(EXCLUDED.last_run_id, requisite_observations.last_run_id)`
+const deleteRelationObservations = `DELETE FROM relation_observations WHERE course_node_id = $1 AND quarter_code = $2`
+const insertRelationObservation = `INSERT INTO relation_observations (course_node_id, quarter_code, source_id, target_id, enforced, prereq, coreq, minimum_grade, run_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) ON CONFLICT DO NOTHING`
+
+// Each course's requisites are those read in the latest quarter at or before the given one that they were read in
+const listRelationsAsOf = `SELECT DISTINCT relation_observations.source_id, relation_observations.target_id, relation_observations.enforced, relation_observations.prereq, relation_observations.coreq, relation_observations.minimum_grade FROM relation_observations JOIN (SELECT course_node_id, max(quarter_rank(quarter_code)) AS rank FROM requisite_observations WHERE quarter_rank(quarter_code) <= quarter_rank($1) GROUP BY course_node_id) latest ON relation_observations.course_node_id = latest.course_node_id AND quarter_rank(relation_observations.quarter_code) = latest.rank ORDER BY relation_observations.source_id, relation_observations.target_id`
+const listRequisiteQuarters = `SELECT quarter_code FROM requisite_observations WHERE course_node_id = $1 ORDER BY quarter_rank(quarter_code)`
+const listRelationObservations = `SELECT quarter_code, source_id, target_id, enforced, prereq, coreq, minimum_grade FROM relation_observations WHERE course_node_id = $1`
+
+// A relation read for a course in a quarter
+type relationObservation struct {
+	QuarterCode string
+	Relation    Relation
+}
+
+func relationKeyOf(relation Relation) relationKey {
+	return relationKey{
+		relation.SourceId,
+		relation.TargetId,
+		FormatOptionalBoolean(relation.Enforced),
+		FormatOptionalBoolean(relation.Prereq),
+		FormatOptionalBoolean(relation.Coreq),
+		FormatOptionalString(relation.MinimumGrade),
+	}
+}
+
+func relationOf(key relationKey) Relation {
+	return Relation{
+		SourceId:     key.sourceId,
+		TargetId:     key.targetId,
+		Enforced:     ParseOptionalBoolean(key.enforced),
+		Prereq:       ParseOptionalBoolean(key.prereq),
+		Coreq:        ParseOptionalBoolean(key.coreq),
+		MinimumGrade: ParseOptionalString(key.minimumGrade),
+	}
+}
+
+// Splits each relation's quarters into runs of consecutive quarters the course's requisites were read in,
+// given those quarters ordered by quarter rank
+func relationVersions(courseNodeId string, quarterCodes []string, observations []relationObservation) []RelationVersion {
+	observed := make(map[string]map[relationKey]bool)
+	var keys []relationKey
+	seen := make(map[relationKey]bool)
+	for _, observation := range observations {
+		key := relationKeyOf(observation.Relation)
+		if observed[observation.QuarterCode] == nil {
+			observed[observation.QuarterCode] = make(map[relationKey]bool)
+		}
+		observed[observation.QuarterCode][key] = true
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	var versions []RelationVersion
+	for _, key := range keys {
+		var version *RelationVersion
+		for _, quarterCode := range quarterCodes {
+			if !observed[quarterCode][key] {
+				version = nil
+				continue
+			}
+			if version == nil {
+				versions = append(versions, RelationVersion{Relation: relationOf(key), CourseNodeId: courseNodeId, ValidFrom: quarterCode})
+				version = &versions[len(versions)-1]
+			}
+			version.ValidTo = quarterCode
+		}
+	}
+	return versions
+}
+
+// Replaces the relations read earlier for the same course and quarter
+func (d *Postgres) InsertRequisiteObservations(ctx context.Context, observations []RequisiteObservation) error {
+	if len(observations) == 0 {
+		return nil
+	}
+
+	batch := pgx.Batch{}
+	var queuedQueries []*pgx.QueuedQuery
+
+	for _, observation := range observations {
+		queuedQueries = append(queuedQueries, batch.Queue(insertRequisiteObservation, observation.CourseNodeId, observation.QuarterCode, d.RunId))
+		queuedQueries = append(queuedQueries, batch.Queue(deleteRelationObservations, observation.CourseNodeId, observation.QuarterCode))
+		for _, relation := range observation.Relations {
+			key := relationKeyOf(relation)
+			queuedQueries = append(queuedQueries, batch.Queue(insertRelationObservation, observation.CourseNodeId, observation.QuarterCode, key.sourceId, key.targetId, key.enforced, key.prereq, key.coreq, key.minimumGrade, d.RunId))
+		}
+	}
+
+	for _, queuedQuery := range queuedQueries {
+		queuedQuery.Exec(insertCallback)
+	}
+
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Postgres) ListRelationsAsOf(ctx context.Context, quarterCode string) ([]Relation, error) {
+	sql := listRelationsAsOf
+	rows, err := d.conn().Query(ctx, sql, quarterCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var relations []Relation
+	for rows.Next() {
+		var key relationKey
+		if err := rows.Scan(&key.sourceId, &key.targetId, &key.enforced, &key.prereq, &key.coreq, &key.minimumGrade); err != nil {
+			return nil, err
+		}
+		relations = append(relations, relationOf(key))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return relations, nil
+}
+
+func (d *Postgres) ListRelationHistory(ctx context.Context, course Course) ([]RelationVersion, error) {
+	quarterRows, err := d.conn().Query(ctx, listRequisiteQuarters, course.NodeId)
+	if err != nil {
+		return nil, err
+	}
+	quarterCodes, err := pgx.CollectRows(quarterRows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := d.conn().Query(ctx, listRelationObservations, course.NodeId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var observations []relationObservation
+	for rows.Next() {
+		var quarterCode string
+		var key relationKey
+		if err := rows.Scan(&quarterCode, &key.sourceId, &key.targetId, &key.enforced, &key.prereq, &key.coreq, &key.minimumGrade); err != nil {
+			return nil, err
+		}
+		observations = append(observations, relationObservation{QuarterCode: quarterCode, Relation: relationOf(key)})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return relationVersions(course.NodeId, quarterCodes, observations), nil
+}
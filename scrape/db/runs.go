@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"runtime/debug"
+	"time"
+)
+
+const insertScrapeRun = `INSERT INTO scrape_runs (stage, parameters, git_revision) VALUES ($1, $2, $3) RETURNING id`
+const finishScrapeRun = `UPDATE scrape_runs SET finished_at=now(), counts=$2, changed=$3, error=$4 WHERE id = $1`
+const listScrapeRuns = `SELECT id, stage, started_at, finished_at, parameters, git_revision, counts, changed, error FROM scrape_runs ORDER BY id DESC LIMIT $1`
+
+type ScrapeRun struct {
+	Id          int64
+	Stage       string
+	StartedAt   time.Time
+	FinishedAt  *time.Time
+	Parameters  map[string]any
+	GitRevision string
+	Counts      map[string]int
+	Changed     []string
+	Error       string
+}
+
+// What a run reports when it finishes
+type RunSummary struct {
+	Counts  map[string]int
+	Changed []string // Units whose content changed, e.g. "24F COM SCI 31"
+}
+
+func (s RunSummary) withDefaults() RunSummary {
+	if s.Counts == nil {
+		s.Counts = map[string]int{}
+	}
+	if s.Changed == nil {
+		s.Changed = []string{}
+	}
+	return s
+}
+
+// Revision of the source tree this binary was built from, suffixed with -dirty if it had local changes
+func GitRevision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+
+	var revision string
+	var modified bool
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision != "" && modified {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+func (d *Postgres) StartScrapeRun(ctx context.Context, stage string, parameters map[string]any) (int64, error) {
+	if parameters == nil {
+		parameters = map[string]any{}
+	}
+
+	var id int64
+	err := d.conn().QueryRow(ctx, insertScrapeRun, stage, parameters, GitRevision()).Scan(&id)
+	return id, err
+}
+
+func (d *Postgres) FinishScrapeRun(ctx context.Context, id int64, summary RunSummary, runErr error) error {
+	summary = summary.withDefaults()
+
+	var errText string
+	if runErr != nil {
+		errText = runErr.Error()
+	}
+
+	_, err := d.conn().Exec(ctx, finishScrapeRun, id, summary.Counts, summary.Changed, errText)
+	return err
+}
+
+func (d *Postgres) ListScrapeRuns(ctx context.Context, limit int) ([]ScrapeRun, error) {
+	sql := listScrapeRuns
+	rows, err := d.conn().Query(ctx, sql, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []ScrapeRun
+	for rows.Next() {
+		var run ScrapeRun
+		if err := rows.Scan(&run.Id, &run.Stage, &run.StartedAt, &run.FinishedAt, &run.Parameters, &run.GitRevision, &run.Counts, &run.Changed, &run.Error); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const insertSection = `INSERT INTO sections (id, quarter_code, subject_area_code, catalog_number, activity, number, class_id, units, instructors, parent_id, first_run_id, last_run_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11) ON CONFLICT (id) DO UPDATE SET class_id=EXCLUDED.class_id, units=EXCLUDED.units, instructors=EXCLUDED.instructors, parent_id=EXCLUDED.parent_id, last_run_id=COALESCE(EXCLUDED.last_run_id, sections.last_run_id)`
+const listSections = `SELECT id, quarter_code, subject_area_code, catalog_number, activity, number, class_id, units, instructors, parent_id FROM sections WHERE quarter_code = $1 AND subject_area_code = $2 ORDER BY catalog_number, id`
+
+const insertMeeting = `INSERT INTO meetings (section_id, position, days, start_time, end_time, location, building, room, first_run_id, last_run_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9) ON CONFLICT (section_id, position) DO UPDATE SET days=EXCLUDED.days, start_time=EXCLUDED.start_time, end_time=EXCLUDED.end_time, location=EXCLUDED.location, building=EXCLUDED.building, room=EXCLUDED.room, last_run_id=COALESCE(EXCLUDED.last_run_id, meetings.last_run_id)`
+const deleteMeetings = `DELETE FROM meetings WHERE section_id IN (SELECT id FROM sections WHERE quarter_code = $1 AND subject_area_code = $2 AND catalog_number = $3)`
+const listMeetings = `SELECT meetings.section_id, meetings.position, meetings.days, meetings.start_time, meetings.end_time, meetings.location, meetings.building, meetings.room FROM meetings JOIN sections ON meetings.section_id = sections.id WHERE sections.quarter_code = $1 AND sections.subject_area_code = $2 ORDER BY meetings.section_id, meetings.position`
+
+func sectionArgs(section Section, runId *int64) []any {
+	return []any{section.Id, section.QuarterCode, section.SubjectAreaCode, section.CatalogNumber, section.Activity, section.Number, section.ClassId, section.Units, section.Instructors, section.ParentId, runId}
+}
+
+func meetingArgs(meeting Meeting, runId *int64) []any {
+	return []any{meeting.SectionId, meeting.Position, meeting.Days, meeting.StartTime, meeting.EndTime, meeting.Location, meeting.Building, meeting.Room, runId}
+}
+
+// The offerings sections belong to, each once
+func sectionOfferings(sections []Section) []QuarterCourse {
+	seen := make(map[QuarterCourse]bool)
+	var offerings []QuarterCourse
+	for _, section := range sections {
+		offering := QuarterCourse{QuarterCode: section.QuarterCode, SubjectAreaCode: section.SubjectAreaCode, CatalogNumber: section.CatalogNumber}
+		if !seen[offering] {
+			seen[offering] = true
+			offerings = append(offerings, offering)
+		}
+	}
+	return offerings
+}
+
+// Lectures must come before their discussions and labs
+func (d *Postgres) InsertSections(ctx context.Context, sections []Section) error {
+	if len(sections) == 0 {
+		return nil
+	}
+
+	batch := pgx.Batch{}
+	var queuedQueries []*pgx.QueuedQuery
+
+	for _, section := range sections {
+		queuedQueries = append(queuedQueries, batch.Queue(insertSection, sectionArgs(section, d.RunId)...))
+	}
+
+	for _, queuedQuery := range queuedQueries {
+		queuedQuery.Exec(insertCallback)
+	}
+
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Postgres) ListSections(ctx context.Context, quarterCode, subjectAreaCode string) ([]Section, error) {
+	sql := listSections
+	rows, err := d.conn().Query(ctx, sql, quarterCode, subjectAreaCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sections []Section
+	for rows.Next() {
+		var section Section
+		if err := rows.Scan(&section.Id, &section.QuarterCode, &section.SubjectAreaCode, &section.CatalogNumber, &section.Activity, &section.Number, &section.ClassId, &section.Units, &section.Instructors, &section.ParentId); err != nil {
+			return nil, err
+		}
+		sections = append(sections, section)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sections, nil
+}
+
+// Replaces the meetings of the offerings sections belong to
+func (d *Postgres) InsertMeetings(ctx context.Context, sections []Section, meetings []Meeting) error {
+	if len(sections) == 0 && len(meetings) == 0 {
+		return nil
+	}
+
+	batch := pgx.Batch{}
+	var queuedQueries []*pgx.QueuedQuery
+
+	for _, offering := range sectionOfferings(sections) {
+		queuedQueries = append(queuedQueries, batch.Queue(deleteMeetings, offering.QuarterCode, offering.SubjectAreaCode, offering.CatalogNumber))
+	}
+	for _, meeting := range meetings {
+		queuedQueries = append(queuedQueries, batch.Queue(insertMeeting, meetingArgs(meeting, d.RunId)...))
+	}
+
+	for _, queuedQuery := range queuedQueries {
+		queuedQuery.Exec(insertCallback)
+	}
+
+	if err := d.conn().SendBatch(ctx, &batch).Close(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (d *Postgres) ListMeetings(ctx context.Context, quarterCode, subjectAreaCode string) ([]Meeting, error) {
+	sql := listMeetings
+	rows, err := d.conn().Query(ctx, sql, quarterCode, subjectAreaCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var meetings []Meeting
+	for rows.Next() {
+		var meeting Meeting
+		if err := rows.Scan(&meeting.SectionId, &meeting.Position, &meeting.Days, &meeting.StartTime, &meeting.EndTime, &meeting.Location, &meeting.Building, &meeting.Room); err != nil {
+			return nil, err
+		}
+		meetings = append(meetings, meeting)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return meetings, nil
+}
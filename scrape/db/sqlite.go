@@ -0,0 +1,977 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+)
+
+// Lets SQLite share the queries ordering by quarter rank
+func init() {
+	sqlite.MustRegisterDeterministicScalarFunction("quarter_rank", 1, func(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+		code, ok := args[0].(string)
+		if !ok {
+			return nil, nil
+		}
+		return QuarterRank(code), nil
+	})
+}
+
+// Store backed by an embedded SQLite file, for running the pipeline locally
+type SQLite struct {
+	DB    *sql.DB
+	RunId *int64 // Tags written rows when set
+
+	tx *sql.Tx
+}
+
+// Satisfied by both the database and a transaction
+type sqliteQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func OpenSQLite(path string) (*SQLite, error) {
+	database, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)")
+	if err != nil {
+		return nil, err
+	}
+	// SQLite allows one writer at a time, so a single connection serializes writes instead of failing them
+	database.SetMaxOpenConns(1)
+	return &SQLite{DB: database}, nil
+}
+
+func (d *SQLite) conn() sqliteQuerier {
+	if d.tx != nil {
+		return d.tx
+	}
+	return d.DB
+}
+
+// The copy shares the database and any open transaction
+func (d *SQLite) WithRun(runId int64) Store {
+	return &SQLite{DB: d.DB, RunId: &runId, tx: d.tx}
+}
+
+func (d *SQLite) InTx(ctx context.Context, fn func(tx Store) error) error {
+	if d.tx != nil {
+		return fn(d)
+	}
+
+	tx, err := d.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(&SQLite{DB: d.DB, RunId: d.RunId, tx: tx}); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *SQLite) Close() error {
+	return d.DB.Close()
+}
+
+// Runs query once per row of args, in one transaction
+func (d *SQLite) execEach(ctx context.Context, query string, args [][]any) error {
+	if len(args) == 0 {
+		return nil
+	}
+
+	return d.InTx(ctx, func(tx Store) error {
+		conn := tx.(*SQLite).conn()
+		for _, a := range args {
+			if _, err := conn.ExecContext(ctx, query, a...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (d *SQLite) Migrate(ctx context.Context) ([]string, error) {
+	if _, err := d.DB.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version text PRIMARY KEY, applied_at text NOT NULL DEFAULT CURRENT_TIMESTAMP)`); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.DB.QueryContext(ctx, listSchemaMigrations)
+	if err != nil {
+		return nil, err
+	}
+	appliedSet := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		appliedSet[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ms, err := SQLiteMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, migration := range ms {
+		if appliedSet[migration.Version] {
+			continue
+		}
+
+		err := d.InTx(ctx, func(tx Store) error {
+			conn := tx.(*SQLite).conn()
+			if _, err := conn.ExecContext(ctx, migration.Sql); err != nil {
+				return err
+			}
+			_, err := conn.ExecContext(ctx, insertSchemaMigration, migration.Version)
+			return err
+		})
+		if err != nil {
+			return versions, err
+		}
+		versions = append(versions, migration.Version)
+	}
+
+	return versions, nil
+}
+
+func (d *SQLite) ListQuarters(ctx context.Context) ([]Quarter, error) {
+	rows, err := d.conn().QueryContext(ctx, listQuarters)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var quarters []Quarter
+	for rows.Next() {
+		var quarter Quarter
+		if err := rows.Scan(&quarter.Code, &quarter.Name); err != nil {
+			return nil, err
+		}
+		quarters = append(quarters, quarter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return quarters, nil
+}
+
+func (d *SQLite) InsertQuarters(ctx context.Context, quarters []Quarter) error {
+	var args [][]any
+	for _, quarter := range quarters {
+		args = append(args, []any{quarter.Code, quarter.Name, d.RunId})
+	}
+	return d.execEach(ctx, insertQuarter, args)
+}
+
+func (d *SQLite) listSubjectAreas(ctx context.Context, query string, args ...any) ([]SubjectArea, error) {
+	rows, err := d.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subjectAreas []SubjectArea
+	for rows.Next() {
+		var subjectArea SubjectArea
+		if err := rows.Scan(&subjectArea.Code, &subjectArea.Name); err != nil {
+			return nil, err
+		}
+		subjectAreas = append(subjectAreas, subjectArea)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return subjectAreas, nil
+}
+
+func (d *SQLite) ListSubjectAreas(ctx context.Context) ([]SubjectArea, error) {
+	return d.listSubjectAreas(ctx, listSubjectAreas)
+}
+
+func (d *SQLite) InsertSubjectAreas(ctx context.Context, subjectAreas []SubjectArea) error {
+	var args [][]any
+	for _, subjectArea := range subjectAreas {
+		args = append(args, []any{subjectArea.Code, subjectArea.Name, d.RunId})
+	}
+	return d.execEach(ctx, insertSubjectArea, args)
+}
+
+func (d *SQLite) ListQuarterSubjectAreas(ctx context.Context, quarter Quarter) ([]SubjectArea, error) {
+	return d.listSubjectAreas(ctx, listQuarterSubjectAreas, quarter.Code)
+}
+
+func (d *SQLite) InsertQuarterSubjectAreas(ctx context.Context, quarter Quarter, subjectAreas []SubjectArea) error {
+	var args [][]any
+	for _, subjectArea := range subjectAreas {
+		args = append(args, []any{quarter.Code, subjectArea.Code, d.RunId})
+	}
+	return d.execEach(ctx, insertQuarterSubjectArea, args)
+}
+
+func (d *SQLite) InsertNodes(ctx context.Context, nodes []Node) error {
+	var args [][]any
+	for _, node := range nodes {
+		args = append(args, []any{node.Id, string(node.Type), d.RunId})
+	}
+	return d.execEach(ctx, insertNode, args)
+}
+
+func (d *SQLite) InsertCourses(ctx context.Context, courses []Course) error {
+	var args [][]any
+	for _, course := range courses {
+		args = append(args, []any{course.SubjectAreaCode, course.CatalogNumber, course.NodeId, d.RunId})
+	}
+	return d.execEach(ctx, insertCourse, args)
+}
+
+func (d *SQLite) InsertRelations(ctx context.Context, relations []Relation) error {
+	var args [][]any
+	for _, relation := range relations {
+		enforced := FormatOptionalBoolean(relation.Enforced)
+		prereq := FormatOptionalBoolean(relation.Prereq)
+		coreq := FormatOptionalBoolean(relation.Coreq)
+		minimumGrade := FormatOptionalString(relation.MinimumGrade)
+
+		args = append(args, []any{relation.SourceId, relation.TargetId, enforced, prereq, coreq, minimumGrade, d.RunId})
+	}
+	return d.execEach(ctx, insertRelation, args)
+}
+
+func (d *SQLite) InsertCoursesDetails(ctx context.Context, coursesDetails []CourseDetails) error {
+	var args [][]any
+	for _, courseDetails := range coursesDetails {
+		args = append(args, []any{
+			courseDetails.SubjectAreaCode,
+			courseDetails.CatalogNumber,
+			courseDetails.Name,
+			courseDetails.Units,
+			courseDetails.Level,
+			strings.ReplaceAll(courseDetails.Description, "\x00", ""),
+			d.RunId,
+		})
+	}
+	return d.execEach(ctx, insertCourseDetails, args)
+}
+
+func (d *SQLite) ListScrapeProgress(ctx context.Context, stage string) ([]ScrapeProgress, error) {
+	rows, err := d.conn().QueryContext(ctx, listScrapeProgress, stage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var progress []ScrapeProgress
+	for rows.Next() {
+		var p ScrapeProgress
+		if err := rows.Scan(&p.Stage, &p.QuarterCode, &p.SubjectAreaCode, &p.Status, &p.Error); err != nil {
+			return nil, err
+		}
+		progress = append(progress, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return progress, nil
+}
+
+func (d *SQLite) ClearScrapeProgress(ctx context.Context, progress []ScrapeProgress) error {
+	var args [][]any
+	for _, p := range progress {
+		args = append(args, []any{p.Stage, p.QuarterCode, p.SubjectAreaCode})
+	}
+	return d.execEach(ctx, clearScrapeProgress, args)
+}
+
+func (d *SQLite) UpsertScrapeProgress(ctx context.Context, progress ScrapeProgress) error {
+	query := strings.Replace(upsertScrapeProgress, "now()", "CURRENT_TIMESTAMP", 1)
+	_, err := d.conn().ExecContext(ctx, query, progress.Stage, progress.QuarterCode, progress.SubjectAreaCode, string(progress.Status), progress.Error)
+	return err
+}
+
+func (d *SQLite) ListContentHashes(ctx context.Context, quarterCode, subjectAreaCode string) ([]ContentHash, error) {
+	rows, err := d.conn().QueryContext(ctx, listContentHashes, quarterCode, subjectAreaCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var contentHashes []ContentHash
+	for rows.Next() {
+		var contentHash ContentHash
+		if err := rows.Scan(&contentHash.QuarterCode, &contentHash.SubjectAreaCode, &contentHash.CatalogNumber, &contentHash.Hash); err != nil {
+			return nil, err
+		}
+		contentHashes = append(contentHashes, contentHash)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return contentHashes, nil
+}
+
+func (d *SQLite) UpsertContentHashes(ctx context.Context, contentHashes []ContentHash) error {
+	query := strings.Replace(upsertContentHash, "now()", "CURRENT_TIMESTAMP", 1)
+	var args [][]any
+	for _, contentHash := range contentHashes {
+		args = append(args, []any{contentHash.QuarterCode, contentHash.SubjectAreaCode, contentHash.CatalogNumber, contentHash.Hash})
+	}
+	return d.execEach(ctx, query, args)
+}
+
+// Locks not refreshed for this long are taken to be left behind by a crashed process
+const sqliteLockExpiry = 10 * time.Minute
+const sqliteLockRefreshInterval = time.Minute
+
+// Locks are rows, so the holder refreshes its row while it runs and a crashed process's row expires
+func (d *SQLite) TryLock(ctx context.Context, name string) (func(), error) {
+	now := time.Now().UTC()
+	result, err := d.conn().ExecContext(ctx, `DELETE FROM scrape_locks WHERE name = $1 AND julianday(refreshed_at) < julianday($2)`, name, now.Add(-sqliteLockExpiry).Format(time.RFC3339Nano))
+	if err != nil {
+		return nil, err
+	}
+	if expired, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if expired > 0 {
+		log.Printf("Took over expired lock %v\n", name)
+	}
+
+	pid := os.Getpid()
+	result, err = d.conn().ExecContext(ctx, `INSERT INTO scrape_locks (name, acquired_at, pid, refreshed_at) VALUES ($1, $2, $3, $2) ON CONFLICT (name) DO NOTHING`, name, now.Format(time.RFC3339Nano), pid)
+	if err != nil {
+		return nil, err
+	}
+	if inserted, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if inserted == 0 {
+		return nil, ErrLocked
+	}
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(sqliteLockRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if _, err := d.DB.Exec(`UPDATE scrape_locks SET refreshed_at = $1 WHERE name = $2 AND pid = $3`, time.Now().UTC().Format(time.RFC3339Nano), name, pid); err != nil {
+					log.Printf("Unable to refresh lock %v: %v\n", name, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+		// Only this process's row, in case the lock expired and was taken over
+		if _, err := d.DB.Exec(`DELETE FROM scrape_locks WHERE name = $1 AND pid = $2`, name, pid); err != nil {
+			log.Printf("Unable to release lock %v: %v\n", name, err)
+		}
+	}, nil
+}
+
+func (d *SQLite) StartScrapeRun(ctx context.Context, stage string, parameters map[string]any) (int64, error) {
+	if parameters == nil {
+		parameters = map[string]any{}
+	}
+	parametersJson, err := json.Marshal(parameters)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+	err = d.conn().QueryRowContext(
+		ctx,
+		`INSERT INTO scrape_runs (stage, started_at, parameters, git_revision) VALUES ($1, $2, $3, $4) RETURNING id`,
+		stage,
+		time.Now().UTC().Format(time.RFC3339Nano),
+		string(parametersJson),
+		GitRevision(),
+	).Scan(&id)
+	return id, err
+}
+
+func (d *SQLite) FinishScrapeRun(ctx context.Context, id int64, summary RunSummary, runErr error) error {
+	summary = summary.withDefaults()
+	countsJson, err := json.Marshal(summary.Counts)
+	if err != nil {
+		return err
+	}
+	changedJson, err := json.Marshal(summary.Changed)
+	if err != nil {
+		return err
+	}
+
+	var errText string
+	if runErr != nil {
+		errText = runErr.Error()
+	}
+
+	_, err = d.conn().ExecContext(
+		ctx,
+		`UPDATE scrape_runs SET finished_at=$2, counts=$3, changed=$4, error=$5 WHERE id = $1`,
+		id,
+		time.Now().UTC().Format(time.RFC3339Nano),
+		string(countsJson),
+		string(changedJson),
+		errText,
+	)
+	return err
+}
+
+func (d *SQLite) ListScrapeRuns(ctx context.Context, limit int) ([]ScrapeRun, error) {
+	rows, err := d.conn().QueryContext(ctx, listScrapeRuns, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var runs []ScrapeRun
+	for rows.Next() {
+		var run ScrapeRun
+		var startedAt string
+		var finishedAt sql.NullString
+		var parametersJson, countsJson, changedJson string
+		if err := rows.Scan(&run.Id, &run.Stage, &startedAt, &finishedAt, &parametersJson, &run.GitRevision, &countsJson, &changedJson, &run.Error); err != nil {
+			return nil, err
+		}
+
+		if run.StartedAt, err = time.Parse(time.RFC3339Nano, startedAt); err != nil {
+			return nil, err
+		}
+		if finishedAt.Valid {
+			t, err := time.Parse(time.RFC3339Nano, finishedAt.String)
+			if err != nil {
+				return nil, err
+			}
+			run.FinishedAt = &t
+		}
+		if err := json.Unmarshal([]byte(parametersJson), &run.Parameters); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(countsJson), &run.Counts); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(changedJson), &run.Changed); err != nil {
+			return nil, err
+		}
+
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+func (d *SQLite) InsertSections(ctx context.Context, sections []Section) error {
+	var args [][]any
+	for _, section := range sections {
+		args = append(args, sectionArgs(section, d.RunId))
+	}
+	return d.execEach(ctx, insertSection, args)
+}
+
+func (d *SQLite) ListSections(ctx context.Context, quarterCode, subjectAreaCode string) ([]Section, error) {
+	rows, err := d.conn().QueryContext(ctx, listSections, quarterCode, subjectAreaCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sections []Section
+	for rows.Next() {
+		var section Section
+		if err := rows.Scan(&section.Id, &section.QuarterCode, &section.SubjectAreaCode, &section.CatalogNumber, &section.Activity, &section.Number, &section.ClassId, &section.Units, &section.Instructors, &section.ParentId); err != nil {
+			return nil, err
+		}
+		sections = append(sections, section)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sections, nil
+}
+
+func (d *SQLite) InsertMeetings(ctx context.Context, sections []Section, meetings []Meeting) error {
+	if len(sections) == 0 && len(meetings) == 0 {
+		return nil
+	}
+
+	return d.InTx(ctx, func(tx Store) error {
+		conn := tx.(*SQLite).conn()
+		for _, offering := range sectionOfferings(sections) {
+			if _, err := conn.ExecContext(ctx, deleteMeetings, offering.QuarterCode, offering.SubjectAreaCode, offering.CatalogNumber); err != nil {
+				return err
+			}
+		}
+		for _, meeting := range meetings {
+			if _, err := conn.ExecContext(ctx, insertMeeting, meetingArgs(meeting, d.RunId)...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (d *SQLite) ListMeetings(ctx context.Context, quarterCode, subjectAreaCode string) ([]Meeting, error) {
+	rows, err := d.conn().QueryContext(ctx, listMeetings, quarterCode, subjectAreaCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var meetings []Meeting
+	for rows.Next() {
+		var meeting Meeting
+		if err := rows.Scan(&meeting.SectionId, &meeting.Position, &meeting.Days, &meeting.StartTime, &meeting.EndTime, &meeting.Location, &meeting.Building, &meeting.Room); err != nil {
+			return nil, err
+		}
+		meetings = append(meetings, meeting)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return meetings, nil
+}
+
+// Fixed width, unlike RFC 3339 with nanoseconds, so timestamps compare correctly as text
+const sqliteTimestampFormat = "2006-01-02T15:04:05.000000000Z"
+
+func (d *SQLite) InsertEnrollmentSnapshots(ctx context.Context, snapshots []EnrollmentSnapshot) error {
+	var args [][]any
+	for _, snapshot := range snapshots {
+		args = append(args, []any{snapshot.SectionId, snapshot.ObservedAt.UTC().Format(sqliteTimestampFormat), snapshot.Status, snapshot.Enrolled, snapshot.Capacity, snapshot.Waitlisted, snapshot.WaitlistCapacity, d.RunId})
+	}
+	return d.execEach(ctx, insertEnrollmentSnapshot, args)
+}
+
+func (d *SQLite) ListEnrollmentSnapshots(ctx context.Context, quarterCode, subjectAreaCode string, from, until time.Time) ([]EnrollmentSnapshot, error) {
+	rows, err := d.conn().QueryContext(ctx, listEnrollmentSnapshots, quarterCode, subjectAreaCode, from.UTC().Format(sqliteTimestampFormat), until.UTC().Format(sqliteTimestampFormat))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []EnrollmentSnapshot
+	for rows.Next() {
+		var snapshot EnrollmentSnapshot
+		var observedAt string
+		if err := rows.Scan(&snapshot.SectionId, &observedAt, &snapshot.Status, &snapshot.Enrolled, &snapshot.Capacity, &snapshot.Waitlisted, &snapshot.WaitlistCapacity); err != nil {
+			return nil, err
+		}
+		if snapshot.ObservedAt, err = time.Parse(sqliteTimestampFormat, observedAt); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+func (d *SQLite) InsertQuarterCourses(ctx context.Context, quarterCourses []QuarterCourse) error {
+	var args [][]any
+	for _, quarterCourse := range quarterCourses {
+		args = append(args, []any{quarterCourse.QuarterCode, quarterCourse.SubjectAreaCode, quarterCourse.CatalogNumber, d.RunId})
+	}
+	return d.execEach(ctx, insertQuarterCourse, args)
+}
+
+func (d *SQLite) ListCourseOfferings(ctx context.Context, course Course) ([]Quarter, error) {
+	rows, err := d.conn().QueryContext(ctx, listCourseOfferings, course.SubjectAreaCode, course.CatalogNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var quarters []Quarter
+	for rows.Next() {
+		var quarter Quarter
+		if err := rows.Scan(&quarter.Code, &quarter.Name); err != nil {
+			return nil, err
+		}
+		quarters = append(quarters, quarter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return quarters, nil
+}
+
+func (d *SQLite) ListQuarterCourses(ctx context.Context, quarter Quarter, subjectArea SubjectArea) ([]Course, error) {
+	rows, err := d.conn().QueryContext(ctx, listQuarterCourses, quarter.Code, subjectArea.Code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var courses []Course
+	for rows.Next() {
+		var course Course
+		if err := rows.Scan(&course.SubjectAreaCode, &course.CatalogNumber); err != nil {
+			return nil, err
+		}
+		course.NodeId = ValueNodeId(course.SubjectAreaCode, course.CatalogNumber)
+		courses = append(courses, course)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return courses, nil
+}
+
+func (d *SQLite) InsertCourseEquivalences(ctx context.Context, courseEquivalences []CourseEquivalence) error {
+	var args [][]any
+	for _, e := range courseEquivalences {
+		args = append(args, []any{e.SubjectAreaCode, e.CatalogNumber, e.EquivalentSubjectAreaCode, e.EquivalentCatalogNumber, e.Kind, d.RunId})
+	}
+	return d.execEach(ctx, insertCourseEquivalence, args)
+}
+
+func (d *SQLite) ListCourseEquivalents(ctx context.Context, course Course) ([]Course, error) {
+	rows, err := d.conn().QueryContext(ctx, listCourseEquivalents, course.SubjectAreaCode, course.CatalogNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var courses []Course
+	for rows.Next() {
+		var equivalent Course
+		if err := rows.Scan(&equivalent.SubjectAreaCode, &equivalent.CatalogNumber); err != nil {
+			return nil, err
+		}
+		equivalent.NodeId = ValueNodeId(equivalent.SubjectAreaCode, equivalent.CatalogNumber)
+		courses = append(courses, equivalent)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return courses, nil
+}
+
+func (d *SQLite) InsertCourseGeCategories(ctx context.Context, courseGeCategories []CourseGeCategory) error {
+	var args [][]any
+	for _, c := range courseGeCategories {
+		args = append(args, []any{c.QuarterCode, c.SubjectAreaCode, c.CatalogNumber, c.Foundation, c.Category, d.RunId})
+	}
+	return d.execEach(ctx, insertCourseGeCategory, args)
+}
+
+func (d *SQLite) ListCourseGeCategories(ctx context.Context, quarter Quarter, subjectArea SubjectArea) ([]CourseGeCategory, error) {
+	rows, err := d.conn().QueryContext(ctx, listCourseGeCategories, quarter.Code, subjectArea.Code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var courseGeCategories []CourseGeCategory
+	for rows.Next() {
+		var c CourseGeCategory
+		if err := rows.Scan(&c.QuarterCode, &c.SubjectAreaCode, &c.CatalogNumber, &c.Foundation, &c.Category); err != nil {
+			return nil, err
+		}
+		courseGeCategories = append(courseGeCategories, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return courseGeCategories, nil
+}
+
+func (d *SQLite) ListGeQuarterCourses(ctx context.Context, quarter Quarter, subjectArea SubjectArea, foundation, category string) ([]Course, error) {
+	rows, err := d.conn().QueryContext(ctx, listGeQuarterCourses, quarter.Code, subjectArea.Code, foundation, category)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var courses []Course
+	for rows.Next() {
+		var course Course
+		if err := rows.Scan(&course.SubjectAreaCode, &course.CatalogNumber); err != nil {
+			return nil, err
+		}
+		course.NodeId = ValueNodeId(course.SubjectAreaCode, course.CatalogNumber)
+		courses = append(courses, course)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return courses, nil
+}
+
+// Replaces the relations read earlier for the same course and quarter
+func (d *SQLite) InsertRequisiteObservations(ctx context.Context, observations []RequisiteObservation) error {
+	if len(observations) == 0 {
+		return nil
+	}
+
+	return d.InTx(ctx, func(tx Store) error {
+		conn := tx.(*SQLite).conn()
+		for _, observation := range observations {
+			if _, err := conn.ExecContext(ctx, insertRequisiteObservation, observation.CourseNodeId, observation.QuarterCode, d.RunId); err != nil {
+				return err
+			}
+			if _, err := conn.ExecContext(ctx, deleteRelationObservations, observation.CourseNodeId, observation.QuarterCode); err != nil {
+				return err
+			}
+			for _, relation := range observation.Relations {
+				key := relationKeyOf(relation)
+				if _, err := conn.ExecContext(ctx, insertRelationObservation, observation.CourseNodeId, observation.QuarterCode, key.sourceId, key.targetId, key.enforced, key.prereq, key.coreq, key.minimumGrade, d.RunId); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (d *SQLite) ListRelationsAsOf(ctx context.Context, quarterCode string) ([]Relation, error) {
+	rows, err := d.conn().QueryContext(ctx, listRelationsAsOf, quarterCode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var relations []Relation
+	for rows.Next() {
+		var key relationKey
+		if err := rows.Scan(&key.sourceId, &key.targetId, &key.enforced, &key.prereq, &key.coreq, &key.minimumGrade); err != nil {
+			return nil, err
+		}
+		relations = append(relations, relationOf(key))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return relations, nil
+}
+
+func (d *SQLite) ListRelationHistory(ctx context.Context, course Course) ([]RelationVersion, error) {
+	quarterRows, err := d.conn().QueryContext(ctx, listRequisiteQuarters, course.NodeId)
+	if err != nil {
+		return nil, err
+	}
+	var quarterCodes []string
+	for quarterRows.Next() {
+		var quarterCode string
+		if err := quarterRows.Scan(&quarterCode); err != nil {
+			quarterRows.Close()
+			return nil, err
+		}
+		quarterCodes = append(quarterCodes, quarterCode)
+	}
+	quarterRows.Close()
+	if err := quarterRows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err := d.conn().QueryContext(ctx, listRelationObservations, course.NodeId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var observations []relationObservation
+	for rows.Next() {
+		var quarterCode string
+		var key relationKey
+		if err := rows.Scan(&quarterCode, &key.sourceId, &key.targetId, &key.enforced, &key.prereq, &key.coreq, &key.minimumGrade); err != nil {
+			return nil, err
+		}
+		observations = append(observations, relationObservation{QuarterCode: quarterCode, Relation: relationOf(key)})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return relationVersions(course.NodeId, quarterCodes, observations), nil
+}
+
+func (d *SQLite) InsertInstructors(ctx context.Context, instructors []Instructor) error {
+	var args [][]any
+	for _, instructor := range instructors {
+		args = append(args, []any{instructor.Id, instructor.Name, d.RunId})
+	}
+	return d.execEach(ctx, insertInstructor, args)
+}
+
+func (d *SQLite) InsertSectionInstructors(ctx context.Context, sectionInstructors []SectionInstructor) error {
+	var args [][]any
+	for _, sectionInstructor := range sectionInstructors {
+		args = append(args, []any{sectionInstructor.SectionId, sectionInstructor.InstructorId, d.RunId})
+	}
+	return d.execEach(ctx, insertSectionInstructor, args)
+}
+
+func (d *SQLite) listTeachings(ctx context.Context, query string, args ...any) ([]Teaching, error) {
+	rows, err := d.conn().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var teachings []Teaching
+	for rows.Next() {
+		var teaching Teaching
+		if err := rows.Scan(&teaching.InstructorId, &teaching.InstructorName, &teaching.QuarterCode, &teaching.SubjectAreaCode, &teaching.CatalogNumber); err != nil {
+			return nil, err
+		}
+		teachings = append(teachings, teaching)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return teachings, nil
+}
+
+func (d *SQLite) ListCourseTeachings(ctx context.Context, course Course) ([]Teaching, error) {
+	return d.listTeachings(ctx, listCourseTeachings, course.SubjectAreaCode, course.CatalogNumber)
+}
+
+func (d *SQLite) ListInstructorTeachings(ctx context.Context, instructorId string) ([]Teaching, error) {
+	return d.listTeachings(ctx, listInstructorTeachings, instructorId)
+}
+
+func (d *SQLite) InsertFinalExams(ctx context.Context, finalExams []FinalExam) error {
+	var args [][]any
+	for _, finalExam := range finalExams {
+		args = append(args, finalExamArgs(finalExam, d.RunId))
+	}
+	return d.execEach(ctx, insertFinalExam, args)
+}
+
+func (d *SQLite) ListFinalExams(ctx context.Context, sectionIds []string) ([]FinalExam, error) {
+	var finalExams []FinalExam
+	for _, sectionId := range sectionIds {
+		var finalExam FinalExam
+		err := d.conn().QueryRowContext(ctx, getFinalExam, sectionId).Scan(&finalExam.SectionId, &finalExam.Date, &finalExam.StartTime, &finalExam.EndTime, &finalExam.Location, &finalExam.Building, &finalExam.Room)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		finalExams = append(finalExams, finalExam)
+	}
+	return finalExams, nil
+}
+
+// Replaces the restrictions stored for the same offering
+func (d *SQLite) InsertOfferingDetails(ctx context.Context, offeringsDetails []OfferingDetails) error {
+	if len(offeringsDetails) == 0 {
+		return nil
+	}
+
+	return d.InTx(ctx, func(tx Store) error {
+		conn := tx.(*SQLite).conn()
+		for _, offeringDetails := range offeringsDetails {
+			key := []any{offeringDetails.QuarterCode, offeringDetails.SubjectAreaCode, offeringDetails.CatalogNumber}
+			if _, err := conn.ExecContext(ctx, insertOfferingDetails, offeringDetailsArgs(offeringDetails, d.RunId)...); err != nil {
+				return err
+			}
+			if _, err := conn.ExecContext(ctx, deleteOfferingRestrictions, key...); err != nil {
+				return err
+			}
+			for position, restriction := range offeringDetails.Restrictions {
+				if _, err := conn.ExecContext(ctx, insertOfferingRestriction, append(key, position, string(restriction.Kind), restriction.Description)...); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Returns false if the offering's class detail page was not scraped
+func (d *SQLite) GetOfferingDetails(ctx context.Context, quarterCourse QuarterCourse) (OfferingDetails, bool, error) {
+	key := []any{quarterCourse.QuarterCode, quarterCourse.SubjectAreaCode, quarterCourse.CatalogNumber}
+
+	var offeringDetails OfferingDetails
+	var gradingBasis string
+	err := d.conn().QueryRowContext(ctx, getOfferingDetails, key...).Scan(&offeringDetails.QuarterCode, &offeringDetails.SubjectAreaCode, &offeringDetails.CatalogNumber, &gradingBasis, &offeringDetails.Grading, &offeringDetails.FeeCents, &offeringDetails.Impacted, &offeringDetails.Notes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return OfferingDetails{}, false, nil
+	}
+	if err != nil {
+		return OfferingDetails{}, false, err
+	}
+	offeringDetails.GradingBasis = GradingBasis(gradingBasis)
+
+	rows, err := d.conn().QueryContext(ctx, listOfferingRestrictions, key...)
+	if err != nil {
+		return OfferingDetails{}, false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var restriction Restriction
+		var kind string
+		if err := rows.Scan(&kind, &restriction.Description); err != nil {
+			return OfferingDetails{}, false, err
+		}
+		restriction.Kind = RestrictionKind(kind)
+		offeringDetails.Restrictions = append(offeringDetails.Restrictions, restriction)
+	}
+
+	if err := rows.Err(); err != nil {
+		return OfferingDetails{}, false, err
+	}
+
+	return offeringDetails, true, nil
+}
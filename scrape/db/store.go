@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// The reading half of Store
+type Reader interface {
+	ListQuarters(ctx context.Context) ([]Quarter, error) // Ordered by quarter rank
+	ListSubjectAreas(ctx context.Context) ([]SubjectArea, error)
+	ListQuarterSubjectAreas(ctx context.Context, quarter Quarter) ([]SubjectArea, error)
+	// The requisite graph as it stood in the given quarter, by quarter rank
+	ListRelationsAsOf(ctx context.Context, quarterCode string) ([]Relation, error)
+	ListRelationHistory(ctx context.Context, course Course) ([]RelationVersion, error)
+	ListSections(ctx context.Context, quarterCode, subjectAreaCode string) ([]Section, error)
+	ListMeetings(ctx context.Context, quarterCode, subjectAreaCode string) ([]Meeting, error)
+	ListCourseTeachings(ctx context.Context, course Course) ([]Teaching, error)           // Ordered by quarter rank
+	ListInstructorTeachings(ctx context.Context, instructorId string) ([]Teaching, error) // Ordered by quarter rank
+	ListFinalExams(ctx context.Context, sectionIds []string) ([]FinalExam, error)         // Sections without a final exam are left out
+	GetOfferingDetails(ctx context.Context, quarterCourse QuarterCourse) (OfferingDetails, bool, error)
+	ListCourseOfferings(ctx context.Context, course Course) ([]Quarter, error) // Ordered by quarter rank
+	ListQuarterCourses(ctx context.Context, quarter Quarter, subjectArea SubjectArea) ([]Course, error)
+	// Including equivalents of equivalents, but not the course itself
+	ListCourseEquivalents(ctx context.Context, course Course) ([]Course, error)
+	ListCourseGeCategories(ctx context.Context, quarter Quarter, subjectArea SubjectArea) ([]CourseGeCategory, error)
+	// Offered courses counting towards a GE foundation, narrowed to one of its categories unless category is empty
+	ListGeQuarterCourses(ctx context.Context, quarter Quarter, subjectArea SubjectArea, foundation, category string) ([]Course, error)
+	// Observed from from, inclusive, until until, exclusive
+	ListEnrollmentSnapshots(ctx context.Context, quarterCode, subjectAreaCode string, from, until time.Time) ([]EnrollmentSnapshot, error)
+
+	ListScrapeProgress(ctx context.Context, stage string) ([]ScrapeProgress, error)
+	ListContentHashes(ctx context.Context, quarterCode, subjectAreaCode string) ([]ContentHash, error)
+
+	ListScrapeRuns(ctx context.Context, limit int) ([]ScrapeRun, error)
+
+	Close() error
+}
+
+// Where scrapers read their input and write what they scrape
+type Store interface {
+	Reader
+
+	InsertQuarters(ctx context.Context, quarters []Quarter) error
+	InsertSubjectAreas(ctx context.Context, subjectAreas []SubjectArea) error
+	InsertQuarterSubjectAreas(ctx context.Context, quarter Quarter, subjectAreas []SubjectArea) error
+	InsertNodes(ctx context.Context, nodes []Node) error
+	InsertCourses(ctx context.Context, courses []Course) error
+	InsertRelations(ctx context.Context, relations []Relation) error
+	InsertRequisiteObservations(ctx context.Context, observations []RequisiteObservation) error
+	InsertCoursesDetails(ctx context.Context, coursesDetails []CourseDetails) error
+	InsertSections(ctx context.Context, sections []Section) error
+	// Replaces the meetings of every offering sections belong to, so sections no longer meeting are cleared
+	InsertMeetings(ctx context.Context, sections []Section, meetings []Meeting) error
+	InsertInstructors(ctx context.Context, instructors []Instructor) error
+	InsertSectionInstructors(ctx context.Context, sectionInstructors []SectionInstructor) error
+	InsertFinalExams(ctx context.Context, finalExams []FinalExam) error
+	InsertOfferingDetails(ctx context.Context, offeringsDetails []OfferingDetails) error
+	InsertQuarterCourses(ctx context.Context, quarterCourses []QuarterCourse) error
+	InsertCourseEquivalences(ctx context.Context, courseEquivalences []CourseEquivalence) error
+	InsertCourseGeCategories(ctx context.Context, courseGeCategories []CourseGeCategory) error
+	InsertEnrollmentSnapshots(ctx context.Context, snapshots []EnrollmentSnapshot) error
+
+	// Deletes the progress of the given units
+	ClearScrapeProgress(ctx context.Context, progress []ScrapeProgress) error
+	UpsertScrapeProgress(ctx context.Context, progress ScrapeProgress) error
+	UpsertContentHashes(ctx context.Context, contentHashes []ContentHash) error
+
+	// Returns ErrLocked if another process holds the lock; the returned function unlocks it
+	TryLock(ctx context.Context, name string) (func(), error)
+	StartScrapeRun(ctx context.Context, stage string, parameters map[string]any) (int64, error)
+	FinishScrapeRun(ctx context.Context, id int64, summary RunSummary, runErr error) error
+
+	// Returns a store tagging the rows it writes with runId
+	WithRun(runId int64) Store
+	// Commits if fn succeeds and rolls back otherwise, including when ctx is cancelled
+	InTx(ctx context.Context, fn func(tx Store) error) error
+	// Returns the versions of the migrations applied
+	Migrate(ctx context.Context) ([]string, error)
+}
+
+// Opens "sqlite:PATH" with SQLite, "memory:" in memory and anything else as a Postgres connection string
+func Open(ctx context.Context, dsn string) (Store, error) {
+	switch {
+	case strings.HasPrefix(dsn, "sqlite:"):
+		return OpenSQLite(strings.TrimPrefix(dsn, "sqlite:"))
+	case dsn == "memory:":
+		return NewMemory(), nil
+	}
+
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &Postgres{Pool: pool}, nil
+}
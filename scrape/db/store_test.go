@@ -0,0 +1,447 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+var testStores = []struct {
+	name string
+	open func(t *testing.T) Store
+}{
+	{"memory", func(t *testing.T) Store { return NewMemory() }},
+	{"sqlite", func(t *testing.T) Store {
+		d, err := OpenSQLite(filepath.Join(t.TempDir(), "brequin.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}},
+}
+
+var (
+	winter24 = Quarter{Code: "24W", Name: "Winter 2024"}
+	spring24 = Quarter{Code: "24S", Name: "Spring 2024"}
+	summer24 = Quarter{Code: "241", Name: "Summer Session A 2024"}
+	fall24   = Quarter{Code: "24F", Name: "Fall 2024"}
+	winter25 = Quarter{Code: "25W", Name: "Winter 2025"}
+)
+
+// The quarters, subject areas and courses the rows of every test refer to, which SQLite's foreign keys require
+func seedStore(t *testing.T, ctx context.Context, s Store) {
+	t.Helper()
+
+	if err := s.InsertQuarters(ctx, []Quarter{winter24, spring24, summer24, fall24, winter25}); err != nil {
+		t.Fatal(err)
+	}
+	subjectAreaCourses := map[string][]string{
+		"BIOENGR": {"CM121"},
+		"COM SCI": {"1", "31", "32", "CM121", "CM221", "M16"},
+		"MATH":    {"31A"},
+		"PHYSICS": {"1A", "5A", "10", "17"},
+	}
+	var subjectAreas []SubjectArea
+	var nodes []Node
+	var courses []Course
+	for subjectAreaCode, catalogNumbers := range subjectAreaCourses {
+		subjectAreas = append(subjectAreas, SubjectArea{Code: subjectAreaCode, Name: subjectAreaCode})
+		for _, catalogNumber := range catalogNumbers {
+			nodeId := ValueNodeId(subjectAreaCode, catalogNumber)
+			nodes = append(nodes, Node{Id: nodeId, Type: NodeTypeValue})
+			courses = append(courses, Course{SubjectAreaCode: subjectAreaCode, CatalogNumber: catalogNumber, NodeId: nodeId})
+		}
+	}
+	if err := s.InsertSubjectAreas(ctx, subjectAreas); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.InsertNodes(ctx, nodes); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.InsertCourses(ctx, courses); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Every test runs against a fresh, seeded store of each kind
+func TestStores(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, ctx context.Context, s Store)
+	}{
+		{"Sections", testSections},
+		{"EnrollmentSnapshots", testEnrollmentSnapshots},
+		{"QuarterCourses", testQuarterCourses},
+		{"RequisiteObservations", testRequisiteObservations},
+		{"Instructors", testInstructors},
+		{"FinalExams", testFinalExams},
+		{"OfferingDetails", testOfferingDetails},
+		{"GeCategories", testGeCategories},
+		{"CourseEquivalences", testCourseEquivalences},
+	}
+
+	for _, store := range testStores {
+		for _, test := range tests {
+			t.Run(store.name+"/"+test.name, func(t *testing.T) {
+				ctx := context.Background()
+				s := store.open(t)
+				defer s.Close()
+				if _, err := s.Migrate(ctx); err != nil {
+					t.Fatal(err)
+				}
+				seedStore(t, ctx, s)
+
+				test.run(t, ctx, s)
+			})
+		}
+	}
+}
+
+func testSections(t *testing.T, ctx context.Context, s Store) {
+	lectureId := SectionId("24F", "COM SCI", "31", "Lec", "1")
+	discussionId := SectionId("24F", "COM SCI", "31", "Dis", "1A")
+	sections := []Section{
+		{Id: lectureId, QuarterCode: "24F", SubjectAreaCode: "COM SCI", CatalogNumber: "31", Activity: "Lec", Number: "1", ClassId: "187003200", Units: "4.0", Instructors: "Smith, John"},
+		{Id: discussionId, QuarterCode: "24F", SubjectAreaCode: "COM SCI", CatalogNumber: "31", Activity: "Dis", Number: "1A", ClassId: "187003201", Units: "0.0", ParentId: &lectureId},
+	}
+	if err := s.InsertSections(ctx, sections); err != nil {
+		t.Fatal(err)
+	}
+	gotSections, err := s.ListSections(ctx, "24F", "COM SCI")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotSections, []Section{sections[1], sections[0]}) {
+		t.Errorf("ListSections = %+v; want %+v", gotSections, sections)
+	}
+
+	if err := s.InsertMeetings(ctx, sections, []Meeting{
+		{SectionId: lectureId, Position: 0, Days: "MW", StartTime: "10:00", EndTime: "11:50"},
+		{SectionId: lectureId, Position: 1, Days: "F", StartTime: "14:00", EndTime: "14:50"},
+		{SectionId: discussionId, Position: 0, Days: "R", StartTime: "09:00", EndTime: "09:50"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// A later scrape listing fewer meetings replaces the offering's earlier ones, clearing sections left without any
+	if err := s.InsertMeetings(ctx, sections, []Meeting{{SectionId: lectureId, Position: 0, Days: "TR", StartTime: "12:00", EndTime: "13:50"}}); err != nil {
+		t.Fatal(err)
+	}
+	gotMeetings, err := s.ListMeetings(ctx, "24F", "COM SCI")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantMeetings := []Meeting{
+		{SectionId: lectureId, Position: 0, Days: "TR", StartTime: "12:00", EndTime: "13:50"},
+	}
+	if !reflect.DeepEqual(gotMeetings, wantMeetings) {
+		t.Errorf("ListMeetings = %+v; want %+v", gotMeetings, wantMeetings)
+	}
+}
+
+func testEnrollmentSnapshots(t *testing.T, ctx context.Context, s Store) {
+	sectionId := SectionId("24F", "COM SCI", "31", "Lec", "1")
+	if err := s.InsertSections(ctx, []Section{{Id: sectionId, QuarterCode: "24F", SubjectAreaCode: "COM SCI", CatalogNumber: "31", Activity: "Lec", Number: "1"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2024, time.October, 1, 0, 0, 0, 0, time.UTC)
+	snapshots := []EnrollmentSnapshot{
+		{SectionId: sectionId, ObservedAt: day, Status: "Open", Enrolled: 12, Capacity: 40},
+		{SectionId: sectionId, ObservedAt: day.AddDate(0, 0, 1), Status: "Open", Enrolled: 30, Capacity: 40},
+		{SectionId: sectionId, ObservedAt: day.AddDate(0, 0, 2), Status: "Closed", Enrolled: 40, Capacity: 40},
+	}
+	if err := s.InsertEnrollmentSnapshots(ctx, snapshots); err != nil {
+		t.Fatal(err)
+	}
+
+	// From is inclusive and until exclusive
+	got, err := s.ListEnrollmentSnapshots(ctx, "24F", "COM SCI", day, day.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, snapshots[:2]) {
+		t.Errorf("ListEnrollmentSnapshots = %+v; want %+v", got, snapshots[:2])
+	}
+}
+
+func testQuarterCourses(t *testing.T, ctx context.Context, s Store) {
+	if err := s.InsertQuarterCourses(ctx, []QuarterCourse{
+		{QuarterCode: "24F", SubjectAreaCode: "COM SCI", CatalogNumber: "31"},
+		{QuarterCode: "24F", SubjectAreaCode: "COM SCI", CatalogNumber: "1"},
+		{QuarterCode: "24W", SubjectAreaCode: "COM SCI", CatalogNumber: "31"},
+		{QuarterCode: "24F", SubjectAreaCode: "MATH", CatalogNumber: "31A"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	courses, err := s.ListQuarterCourses(ctx, fall24, SubjectArea{Code: "COM SCI"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCourses := []Course{
+		{SubjectAreaCode: "COM SCI", CatalogNumber: "1", NodeId: ValueNodeId("COM SCI", "1")},
+		{SubjectAreaCode: "COM SCI", CatalogNumber: "31", NodeId: ValueNodeId("COM SCI", "31")},
+	}
+	if !reflect.DeepEqual(courses, wantCourses) {
+		t.Errorf("ListQuarterCourses = %+v; want %+v", courses, wantCourses)
+	}
+
+	offerings, err := s.ListCourseOfferings(ctx, Course{SubjectAreaCode: "COM SCI", CatalogNumber: "31"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Quarter{winter24, fall24}; !reflect.DeepEqual(offerings, want) {
+		t.Errorf("ListCourseOfferings = %+v; want %+v", offerings, want)
+	}
+}
+
+func testRequisiteObservations(t *testing.T, ctx context.Context, s Store) {
+	course := Course{SubjectAreaCode: "COM SCI", CatalogNumber: "32", NodeId: ValueNodeId("COM SCI", "32")}
+	yes := true
+	grade := "C-"
+	oldRelation := Relation{SourceId: ValueNodeId("COM SCI", "31"), TargetId: course.NodeId, Enforced: &yes, Prereq: &yes}
+	newRelation := Relation{SourceId: ValueNodeId("COM SCI", "31"), TargetId: course.NodeId, Enforced: &yes, Prereq: &yes, MinimumGrade: &grade}
+
+	if err := s.InsertRelations(ctx, []Relation{oldRelation, newRelation}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.InsertRequisiteObservations(ctx, []RequisiteObservation{
+		{CourseNodeId: course.NodeId, QuarterCode: "24W", Relations: []Relation{oldRelation}},
+		{CourseNodeId: course.NodeId, QuarterCode: "24S", Relations: []Relation{oldRelation}},
+		{CourseNodeId: course.NodeId, QuarterCode: "24F", Relations: []Relation{newRelation}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	relations, err := s.ListRelationsAsOf(ctx, "241")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Relation{oldRelation}; !reflect.DeepEqual(relations, want) {
+		t.Errorf("ListRelationsAsOf(241) = %+v; want %+v", relations, want)
+	}
+
+	versions, err := s.ListRelationHistory(ctx, course)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return QuarterRank(versions[i].ValidFrom) < QuarterRank(versions[j].ValidFrom)
+	})
+	wantVersions := []RelationVersion{
+		{Relation: oldRelation, CourseNodeId: course.NodeId, ValidFrom: "24W", ValidTo: "24S"},
+		{Relation: newRelation, CourseNodeId: course.NodeId, ValidFrom: "24F", ValidTo: "24F"},
+	}
+	if !reflect.DeepEqual(versions, wantVersions) {
+		t.Errorf("ListRelationHistory = %+v; want %+v", versions, wantVersions)
+	}
+}
+
+func testInstructors(t *testing.T, ctx context.Context, s Store) {
+	fallId := SectionId("24F", "COM SCI", "31", "Lec", "1")
+	winterId := SectionId("25W", "COM SCI", "31", "Lec", "1")
+	if err := s.InsertSections(ctx, []Section{
+		{Id: fallId, QuarterCode: "24F", SubjectAreaCode: "COM SCI", CatalogNumber: "31", Activity: "Lec", Number: "1"},
+		{Id: winterId, QuarterCode: "25W", SubjectAreaCode: "COM SCI", CatalogNumber: "31", Activity: "Lec", Number: "1"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	smith := InstructorId("Smith", "j")
+	if err := s.InsertInstructors(ctx, []Instructor{{Id: smith, Name: "Smith, J."}, {Id: smith, Name: "Smith, John"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.InsertSectionInstructors(ctx, []SectionInstructor{
+		{SectionId: fallId, InstructorId: smith},
+		{SectionId: winterId, InstructorId: smith},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Abbreviated and full listings are one instructor, named by the most complete listing
+	teachings, err := s.ListCourseTeachings(ctx, Course{SubjectAreaCode: "COM SCI", CatalogNumber: "31"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Teaching{
+		{InstructorId: smith, InstructorName: "Smith, John", QuarterCode: "24F", SubjectAreaCode: "COM SCI", CatalogNumber: "31"},
+		{InstructorId: smith, InstructorName: "Smith, John", QuarterCode: "25W", SubjectAreaCode: "COM SCI", CatalogNumber: "31"},
+	}
+	if !reflect.DeepEqual(teachings, want) {
+		t.Errorf("ListCourseTeachings = %+v; want %+v", teachings, want)
+	}
+
+	teachings, err = s.ListInstructorTeachings(ctx, smith)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(teachings, want) {
+		t.Errorf("ListInstructorTeachings = %+v; want %+v", teachings, want)
+	}
+}
+
+func testFinalExams(t *testing.T, ctx context.Context, s Store) {
+	finalExams := []FinalExam{
+		{SectionId: SectionId("24F", "COM SCI", "31", "Lec", "1"), Date: "2024-12-09", StartTime: "08:00", EndTime: "11:00", Location: "Boelter Hall 3400", Building: "Boelter Hall", Room: "3400"},
+		{SectionId: SectionId("24F", "MATH", "31A", "Lec", "1"), Date: "2024-12-09", StartTime: "10:00", EndTime: "13:00"},
+		{SectionId: SectionId("24F", "PHYSICS", "1A", "Lec", "1"), Date: "2024-12-10"},
+	}
+	withoutFinal := SectionId("24F", "COM SCI", "32", "Lec", "1")
+	if err := s.InsertSections(ctx, []Section{
+		{Id: finalExams[0].SectionId, QuarterCode: "24F", SubjectAreaCode: "COM SCI", CatalogNumber: "31", Activity: "Lec", Number: "1"},
+		{Id: finalExams[1].SectionId, QuarterCode: "24F", SubjectAreaCode: "MATH", CatalogNumber: "31A", Activity: "Lec", Number: "1"},
+		{Id: finalExams[2].SectionId, QuarterCode: "24F", SubjectAreaCode: "PHYSICS", CatalogNumber: "1A", Activity: "Lec", Number: "1"},
+		{Id: withoutFinal, QuarterCode: "24F", SubjectAreaCode: "COM SCI", CatalogNumber: "32", Activity: "Lec", Number: "1"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.InsertFinalExams(ctx, finalExams); err != nil {
+		t.Fatal(err)
+	}
+
+	// Sections without a final exam are left out
+	got, err := s.ListFinalExams(ctx, []string{finalExams[0].SectionId, withoutFinal, finalExams[1].SectionId, finalExams[2].SectionId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, finalExams) {
+		t.Errorf("ListFinalExams = %+v; want %+v", got, finalExams)
+	}
+
+	conflicts := FindFinalExamConflicts(got)
+	if want := []FinalExamConflict{{First: finalExams[0], Second: finalExams[1]}}; !reflect.DeepEqual(conflicts, want) {
+		t.Errorf("FindFinalExamConflicts = %+v; want %+v", conflicts, want)
+	}
+}
+
+func testOfferingDetails(t *testing.T, ctx context.Context, s Store) {
+	offering := QuarterCourse{QuarterCode: "24F", SubjectAreaCode: "COM SCI", CatalogNumber: "31"}
+	if err := s.InsertQuarterCourses(ctx, []QuarterCourse{offering}); err != nil {
+		t.Fatal(err)
+	}
+	offeringDetails := OfferingDetails{
+		QuarterCode:     offering.QuarterCode,
+		SubjectAreaCode: offering.SubjectAreaCode,
+		CatalogNumber:   offering.CatalogNumber,
+		GradingBasis:    GradingBasisLetter,
+		Grading:         "Letter grade",
+		FeeCents:        2550,
+		Restrictions: []Restriction{
+			{Kind: RestrictionKindMajor, Description: "Major restricted to Computer Science"},
+			{Kind: RestrictionKindClassStanding, Description: "Juniors and seniors only"},
+		},
+	}
+	if err := s.InsertOfferingDetails(ctx, []OfferingDetails{offeringDetails}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A later scrape replaces the offering's restrictions
+	offeringDetails.Restrictions = offeringDetails.Restrictions[:1]
+	if err := s.InsertOfferingDetails(ctx, []OfferingDetails{offeringDetails}); err != nil {
+		t.Fatal(err)
+	}
+	got, exists, err := s.GetOfferingDetails(ctx, offering)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !exists || !reflect.DeepEqual(got, offeringDetails) {
+		t.Errorf("GetOfferingDetails = %+v, %v; want %+v, true", got, exists, offeringDetails)
+	}
+
+	if _, exists, err := s.GetOfferingDetails(ctx, QuarterCourse{QuarterCode: "24F", SubjectAreaCode: "COM SCI", CatalogNumber: "32"}); err != nil || exists {
+		t.Errorf("GetOfferingDetails of an unscraped offering = %v, %v; want false, nil", exists, err)
+	}
+}
+
+func testGeCategories(t *testing.T, ctx context.Context, s Store) {
+	subjectArea := SubjectArea{Code: "PHYSICS"}
+	if err := s.InsertQuarterCourses(ctx, []QuarterCourse{
+		{QuarterCode: "24F", SubjectAreaCode: "PHYSICS", CatalogNumber: "1A"},
+		{QuarterCode: "24F", SubjectAreaCode: "PHYSICS", CatalogNumber: "5A"},
+		{QuarterCode: "24F", SubjectAreaCode: "PHYSICS", CatalogNumber: "10"},
+		{QuarterCode: "24F", SubjectAreaCode: "PHYSICS", CatalogNumber: "17"},
+		// Offered in another quarter only
+		{QuarterCode: "24W", SubjectAreaCode: "PHYSICS", CatalogNumber: "10"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	courseGeCategories := []CourseGeCategory{
+		{QuarterCode: "24F", SubjectAreaCode: "PHYSICS", CatalogNumber: "5A", Foundation: "SI", Category: "PS"},
+		{QuarterCode: "24F", SubjectAreaCode: "PHYSICS", CatalogNumber: "17", Foundation: "SI", Category: "LS"},
+		{QuarterCode: "24F", SubjectAreaCode: "PHYSICS", CatalogNumber: "17", Foundation: "SI", Category: "PS"},
+		{QuarterCode: "24W", SubjectAreaCode: "PHYSICS", CatalogNumber: "10", Foundation: "SI", Category: "PS"},
+	}
+	if err := s.InsertCourseGeCategories(ctx, courseGeCategories); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := s.ListCourseGeCategories(ctx, fall24, subjectArea)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []CourseGeCategory{courseGeCategories[1], courseGeCategories[2], courseGeCategories[0]}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListCourseGeCategories = %+v; want %+v", got, want)
+	}
+
+	tests := []struct {
+		foundation     string
+		category       string
+		catalogNumbers []string
+	}{
+		{"SI", "", []string{"17", "5A"}},
+		{"SI", "LS", []string{"17"}},
+		{"AH", "", nil},
+	}
+	for _, test := range tests {
+		courses, err := s.ListGeQuarterCourses(ctx, fall24, subjectArea, test.foundation, test.category)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var catalogNumbers []string
+		for _, course := range courses {
+			catalogNumbers = append(catalogNumbers, course.CatalogNumber)
+		}
+		if !reflect.DeepEqual(catalogNumbers, test.catalogNumbers) {
+			t.Errorf("ListGeQuarterCourses(%q, %q) = %v; want %v", test.foundation, test.category, catalogNumbers, test.catalogNumbers)
+		}
+	}
+}
+
+func testCourseEquivalences(t *testing.T, ctx context.Context, s Store) {
+	pair := func(a, b Course, kind EquivalenceKind) []CourseEquivalence {
+		return []CourseEquivalence{
+			{SubjectAreaCode: a.SubjectAreaCode, CatalogNumber: a.CatalogNumber, EquivalentSubjectAreaCode: b.SubjectAreaCode, EquivalentCatalogNumber: b.CatalogNumber, Kind: kind},
+			{SubjectAreaCode: b.SubjectAreaCode, CatalogNumber: b.CatalogNumber, EquivalentSubjectAreaCode: a.SubjectAreaCode, EquivalentCatalogNumber: a.CatalogNumber, Kind: kind},
+		}
+	}
+	comSci := Course{SubjectAreaCode: "COM SCI", CatalogNumber: "CM121"}
+	bioengr := Course{SubjectAreaCode: "BIOENGR", CatalogNumber: "CM121"}
+	graduate := Course{SubjectAreaCode: "COM SCI", CatalogNumber: "CM221"}
+	unrelated := Course{SubjectAreaCode: "COM SCI", CatalogNumber: "M16"}
+	if err := s.InsertCourseEquivalences(ctx, append(pair(comSci, bioengr, EquivalenceKindMultipleListed), pair(comSci, graduate, EquivalenceKindConcurrent)...)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Equivalents of equivalents are included, but not the course itself
+	equivalents, err := s.ListCourseEquivalents(ctx, bioengr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []Course
+	for _, equivalent := range equivalents {
+		got = append(got, Course{SubjectAreaCode: equivalent.SubjectAreaCode, CatalogNumber: equivalent.CatalogNumber})
+	}
+	sort.Slice(got, func(i, j int) bool {
+		return got[i].CatalogNumber < got[j].CatalogNumber
+	})
+	if want := []Course{comSci, graduate}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListCourseEquivalents = %+v; want %+v", got, want)
+	}
+
+	if equivalents, err := s.ListCourseEquivalents(ctx, unrelated); err != nil || len(equivalents) != 0 {
+		t.Errorf("ListCourseEquivalents of a course without equivalents = %+v, %v; want none", equivalents, err)
+	}
+}
@@ -0,0 +1,168 @@
+package details
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/filter"
+	"github.com/brequin/brequin/scrape/progress"
+	"github.com/brequin/brequin/scrape/report"
+	"github.com/brequin/brequin/scrape/soc"
+)
+
+const subjectAreasPath = "/sis/publicapis/course/getallcourses"
+const courseDetailsPath = "/sis/publicapis/course/getcoursedetail"
+
+type SubjectAreaEntry struct {
+	Code string `json:"subj_area_cd"`
+}
+
+type CourseEntry struct {
+	Title       string `json:"course_title"`
+	Units       string `json:"unt_rng"`
+	Level       string `json:"crs_career_lvl_nm"`
+	Description string `json:"crs_desc"`
+}
+
+func ScrapeCurrentSubjectAreas(ctx context.Context, client *soc.Client) ([]SubjectAreaEntry, error) {
+	responseJson, err := client.Fetch(ctx, client.ApiUrl(subjectAreasPath), nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var subjectAreaEntries []SubjectAreaEntry
+	if err := json.Unmarshal(responseJson, &subjectAreaEntries); err != nil {
+		return nil, err
+	}
+
+	return subjectAreaEntries, nil
+}
+
+func ScrapeCoursesDetails(ctx context.Context, client *soc.Client, subjectAreaCode string) ([]db.CourseDetails, error) {
+	query := url.Values{}
+	query.Add("subjectarea", subjectAreaCode)
+
+	responseJson, err := client.Fetch(ctx, client.ApiUrl(courseDetailsPath), query, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var courseEntries []CourseEntry
+	if err := json.Unmarshal(responseJson, &courseEntries); err != nil {
+		return nil, err
+	}
+
+	var coursesDetails []db.CourseDetails
+	for _, courseEntry := range courseEntries {
+		catalogNumber, name, found := strings.Cut(courseEntry.Title, ". ")
+		if !found {
+			return nil, errors.New("Unable to determine course catalog number and name")
+		}
+
+		level := strings.TrimSuffix(courseEntry.Level, " Courses")
+
+		courseDetails := db.CourseDetails{
+			SubjectAreaCode: strings.TrimSpace(subjectAreaCode),
+			CatalogNumber:   strings.TrimSpace(catalogNumber),
+			Name:            strings.TrimSpace(name),
+			Units:           strings.TrimSpace(courseEntry.Units),
+			Level:           strings.TrimSpace(level),
+			Description:     strings.TrimSpace(courseEntry.Description),
+		}
+		coursesDetails = append(coursesDetails, courseDetails)
+	}
+
+	return coursesDetails, nil
+}
+
+// Returns the number of courses whose details were scraped.
+// Equivalences only name subject areas scraped before, since they are looked up by name.
+func Run(ctx context.Context, client *soc.Client, database db.Store, f *filter.Filter, tracker *progress.Tracker, errs *report.Collector) (int, error) {
+	subjectAreas, err := database.ListSubjectAreas(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if len(subjectAreas) == 0 {
+		log.Println("No subject areas scraped yet; multiple-listed course equivalences will not be recorded")
+	}
+	subjectAreaNameCodes := make(map[string]string)
+	for _, subjectArea := range subjectAreas {
+		subjectAreaNameCodes[subjectArea.Name] = subjectArea.Code
+	}
+
+	subjectAreaEntries, err := ScrapeCurrentSubjectAreas(ctx, client)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	var countMutex sync.Mutex
+
+	var wg sync.WaitGroup
+	for _, subjectAreaEntry := range subjectAreaEntries {
+		if ctx.Err() != nil {
+			break
+		}
+
+		subjectAreaCode := strings.TrimSpace(subjectAreaEntry.Code)
+		if !f.MatchSubjectArea(db.SubjectArea{Code: subjectAreaCode}) || tracker.Skip("", subjectAreaCode) {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(s SubjectAreaEntry) {
+			defer wg.Done()
+
+			coursesDetails, err := ScrapeCoursesDetails(ctx, client, s.Code)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				log.Println("Unable to get course details for subject area: " + s.Code)
+				errs.Add(&report.Error{Stage: "details", Kind: report.Classify(err), SubjectAreaCode: strings.TrimSpace(s.Code), Err: err})
+				tracker.Fail(ctx, "", strings.TrimSpace(s.Code), err)
+				return
+			}
+
+			var courseEquivalences []db.CourseEquivalence
+			for _, courseDetails := range coursesDetails {
+				courseEquivalences = append(courseEquivalences, ParseCourseEquivalences(courseDetails, subjectAreaNameCodes)...)
+			}
+
+			msg := fmt.Sprintf("%v: Scraped details for %v courses, %v equivalences", s.Code, len(coursesDetails), len(courseEquivalences)/2)
+			log.Println(msg)
+
+			err = database.InTx(ctx, func(tx db.Store) error {
+				if err := tx.InsertCoursesDetails(ctx, coursesDetails); err != nil {
+					return err
+				}
+				return tx.InsertCourseEquivalences(ctx, courseEquivalences)
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				errs.Add(&report.Error{Stage: "details", Kind: report.KindDatabase, SubjectAreaCode: strings.TrimSpace(s.Code), Err: err})
+				tracker.Fail(ctx, "", strings.TrimSpace(s.Code), err)
+				return
+			}
+
+			tracker.Complete(ctx, "", strings.TrimSpace(s.Code))
+
+			countMutex.Lock()
+			count += len(coursesDetails)
+			countMutex.Unlock()
+		}(subjectAreaEntry)
+	}
+	wg.Wait()
+
+	return count, ctx.Err()
+}
@@ -0,0 +1,86 @@
+package details
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/brequin/brequin/scrape/courses"
+	"github.com/brequin/brequin/scrape/db"
+)
+
+// e.g. "(Same as Electrical and Computer Engineering M16.)" or "(Same as History M160 and Gender Studies M160.)"
+var sameAsPattern = regexp.MustCompile(`\(Same as ([^)]*)\)`)
+
+// e.g. "Concurrently scheduled with course C211." or "Concurrently scheduled with courses C211, C311."
+var concurrentlyScheduledPattern = regexp.MustCompile(`Concurrently scheduled with courses? ([^.;]*)`)
+
+// A course named in a list such as "History M160, Gender Studies M160 and Asian American Studies M160";
+// the subject area name is empty for courses of the same subject area, as in "courses C211 and C311"
+type listedCourse struct {
+	subjectAreaName string
+	catalogNumber   string
+}
+
+// Subject area names may contain "and", so each course ends at its catalog number, the first word with a digit
+func listedCourses(text string) []listedCourse {
+	var listed []listedCourse
+	var nameWords []string
+	for _, word := range strings.Fields(text) {
+		word = strings.TrimRight(word, ",.")
+		if word == "" || (len(nameWords) == 0 && word == "and") {
+			continue
+		}
+		if !strings.ContainsFunc(word, unicode.IsDigit) {
+			nameWords = append(nameWords, word)
+			continue
+		}
+		listed = append(listed, listedCourse{subjectAreaName: strings.Join(nameWords, " "), catalogNumber: word})
+		nameWords = nil
+	}
+	return listed
+}
+
+func equivalencePair(a, b db.Course, kind db.EquivalenceKind) []db.CourseEquivalence {
+	return []db.CourseEquivalence{
+		{SubjectAreaCode: a.SubjectAreaCode, CatalogNumber: a.CatalogNumber, EquivalentSubjectAreaCode: b.SubjectAreaCode, EquivalentCatalogNumber: b.CatalogNumber, Kind: kind},
+		{SubjectAreaCode: b.SubjectAreaCode, CatalogNumber: b.CatalogNumber, EquivalentSubjectAreaCode: a.SubjectAreaCode, EquivalentCatalogNumber: a.CatalogNumber, Kind: kind},
+	}
+}
+
+// Reads the courses a course's description names as multiple-listed or concurrently scheduled with it, in both directions.
+// Only catalog numbers prefixed M or C on both sides are taken, and subject areas are looked up by name in subjectAreaNameCodes.
+func ParseCourseEquivalences(courseDetails db.CourseDetails, subjectAreaNameCodes map[string]string) []db.CourseEquivalence {
+	course := db.Course{SubjectAreaCode: courseDetails.SubjectAreaCode, CatalogNumber: courseDetails.CatalogNumber}
+	prefix := courses.CatalogNumberPrefix(course.CatalogNumber)
+
+	var equivalences []db.CourseEquivalence
+	if strings.Contains(prefix, "M") {
+		for _, match := range sameAsPattern.FindAllStringSubmatch(courseDetails.Description, -1) {
+			for _, listed := range listedCourses(match[1]) {
+				subjectAreaCode, ok := subjectAreaNameCodes[listed.subjectAreaName]
+				if !ok || !strings.Contains(courses.CatalogNumberPrefix(listed.catalogNumber), "M") {
+					continue
+				}
+				equivalent := db.Course{SubjectAreaCode: subjectAreaCode, CatalogNumber: listed.catalogNumber}
+				if equivalent != course {
+					equivalences = append(equivalences, equivalencePair(course, equivalent, db.EquivalenceKindMultipleListed)...)
+				}
+			}
+		}
+	}
+	if strings.Contains(prefix, "C") {
+		for _, match := range concurrentlyScheduledPattern.FindAllStringSubmatch(courseDetails.Description, -1) {
+			for _, listed := range listedCourses(match[1]) {
+				if listed.subjectAreaName != "" || !strings.Contains(courses.CatalogNumberPrefix(listed.catalogNumber), "C") {
+					continue
+				}
+				equivalent := db.Course{SubjectAreaCode: course.SubjectAreaCode, CatalogNumber: listed.catalogNumber}
+				if equivalent != course {
+					equivalences = append(equivalences, equivalencePair(course, equivalent, db.EquivalenceKindConcurrent)...)
+				}
+			}
+		}
+	}
+	return equivalences
+}
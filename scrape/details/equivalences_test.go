@@ -0,0 +1,84 @@
+package details
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/soc"
+)
+
+// Serves one testdata file for every request
+type fixtureDoer string
+
+func (d fixtureDoer) Do(request *http.Request) (*http.Response, error) {
+	content, err := os.ReadFile(filepath.Join("testdata", string(d)))
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(content)), Request: request}, nil
+}
+
+func TestListedCourses(t *testing.T) {
+	tests := map[string][]listedCourse{
+		"Electrical and Computer Engineering M16.": {{"Electrical and Computer Engineering", "M16"}},
+		"History M160, Gender Studies M160 and Asian American Studies M160": {
+			{"History", "M160"},
+			{"Gender Studies", "M160"},
+			{"Asian American Studies", "M160"},
+		},
+		"C211 and C311": {{"", "C211"}, {"", "C311"}},
+		"C211, C311":    {{"", "C211"}, {"", "C311"}},
+	}
+
+	for text, want := range tests {
+		if got := listedCourses(text); !reflect.DeepEqual(got, want) {
+			t.Errorf("listedCourses(%q) = %+v; want %+v", text, got, want)
+		}
+	}
+}
+
+func TestParseCourseEquivalences(t *testing.T) {
+	client := soc.NewClient()
+	client.ApiBaseUrl = "https://api.test"
+	client.Doer = fixtureDoer("course_details.json")
+
+	coursesDetails, err := ScrapeCoursesDetails(context.Background(), client, "COM SCI")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(coursesDetails) != 3 || coursesDetails[1].CatalogNumber != "M16" || coursesDetails[1].Level != "Lower Division" {
+		t.Fatalf("Courses details = %+v", coursesDetails)
+	}
+
+	subjectAreaNameCodes := map[string]string{
+		"Electrical and Computer Engineering": "EC ENGR",
+		"Bioengineering":                      "BIOENGR",
+		"Computational and Systems Biology":   "C&S BIO",
+	}
+	var equivalences []db.CourseEquivalence
+	for _, courseDetails := range coursesDetails {
+		equivalences = append(equivalences, ParseCourseEquivalences(courseDetails, subjectAreaNameCodes)...)
+	}
+
+	pair := func(subjectAreaCode, catalogNumber, equivalentSubjectAreaCode, equivalentCatalogNumber string, kind db.EquivalenceKind) []db.CourseEquivalence {
+		return []db.CourseEquivalence{
+			{SubjectAreaCode: subjectAreaCode, CatalogNumber: catalogNumber, EquivalentSubjectAreaCode: equivalentSubjectAreaCode, EquivalentCatalogNumber: equivalentCatalogNumber, Kind: kind},
+			{SubjectAreaCode: equivalentSubjectAreaCode, CatalogNumber: equivalentCatalogNumber, EquivalentSubjectAreaCode: subjectAreaCode, EquivalentCatalogNumber: catalogNumber, Kind: kind},
+		}
+	}
+	var want []db.CourseEquivalence
+	want = append(want, pair("COM SCI", "M16", "EC ENGR", "M16", db.EquivalenceKindMultipleListed)...)
+	want = append(want, pair("COM SCI", "CM121", "BIOENGR", "CM121", db.EquivalenceKindMultipleListed)...)
+	want = append(want, pair("COM SCI", "CM121", "C&S BIO", "M121", db.EquivalenceKindMultipleListed)...)
+	want = append(want, pair("COM SCI", "CM121", "COM SCI", "CM221", db.EquivalenceKindConcurrent)...)
+	if !reflect.DeepEqual(equivalences, want) {
+		t.Errorf("Equivalences = %+v; want %+v", equivalences, want)
+	}
+}
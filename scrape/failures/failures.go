@@ -0,0 +1,139 @@
+package failures
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	metaFile       = "meta.json"
+	pageFile       = "page.html"
+	expressionFile = "expression.txt"
+	tokensFile     = "tokens.txt"
+)
+
+// What a course's requisites were parsed from when parsing failed
+type Snapshot struct {
+	QuarterCode     string    `json:"quarter_code"`
+	SubjectAreaCode string    `json:"subject_area_code"`
+	CatalogNumber   string    `json:"catalog_number"`
+	Url             string    `json:"url"`
+	Error           string    `json:"error"`
+	SavedAt         time.Time `json:"saved_at"`
+
+	Page       []byte   `json:"-"`
+	Expression string   `json:"-"` // Empty if the page could not be turned into an expression
+	Tokens     []string `json:"-"`
+}
+
+// Snapshots kept under Dir/QUARTER/SUBJECT/CATALOG, one file per artifact so they can be inspected by hand
+type Snapshots struct {
+	Dir string
+}
+
+func NewSnapshotsFromEnv() Snapshots {
+	return Snapshots{Dir: os.Getenv("SCRAPE_FAILURES_DIR")}
+}
+
+func (s Snapshots) Enabled() bool {
+	return s.Dir != ""
+}
+
+// Subject area codes contain spaces and catalog numbers may contain anything
+func pathPart(part string) string {
+	return strings.NewReplacer(" ", "_", "/", "_", string(filepath.Separator), "_").Replace(part)
+}
+
+func (s Snapshots) path(quarterCode, subjectAreaCode, catalogNumber string) string {
+	return filepath.Join(s.Dir, pathPart(quarterCode), pathPart(subjectAreaCode), pathPart(catalogNumber))
+}
+
+// Replaces any earlier snapshot of the same course
+func (s Snapshots) Save(snapshot Snapshot) error {
+	dir := s.path(snapshot.QuarterCode, snapshot.SubjectAreaCode, snapshot.CatalogNumber)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	if snapshot.SavedAt.IsZero() {
+		snapshot.SavedAt = time.Now()
+	}
+	meta, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	files := map[string][]byte{
+		metaFile:       meta,
+		pageFile:       snapshot.Page,
+		expressionFile: []byte(snapshot.Expression),
+		tokensFile:     []byte(strings.Join(snapshot.Tokens, "\n")),
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s Snapshots) load(dir string) (Snapshot, error) {
+	var snapshot Snapshot
+
+	meta, err := os.ReadFile(filepath.Join(dir, metaFile))
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if err := json.Unmarshal(meta, &snapshot); err != nil {
+		return Snapshot{}, err
+	}
+
+	if snapshot.Page, err = os.ReadFile(filepath.Join(dir, pageFile)); err != nil {
+		return Snapshot{}, err
+	}
+	expression, err := os.ReadFile(filepath.Join(dir, expressionFile))
+	if err != nil {
+		return Snapshot{}, err
+	}
+	snapshot.Expression = string(expression)
+	tokens, err := os.ReadFile(filepath.Join(dir, tokensFile))
+	if err != nil {
+		return Snapshot{}, err
+	}
+	if len(tokens) > 0 {
+		snapshot.Tokens = strings.Split(string(tokens), "\n")
+	}
+
+	return snapshot, nil
+}
+
+// Ordered by quarter, subject area and catalog number directory
+func (s Snapshots) List() ([]Snapshot, error) {
+	metaPaths, err := filepath.Glob(filepath.Join(s.Dir, "*", "*", "*", metaFile))
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+	for _, metaPath := range metaPaths {
+		snapshot, err := s.load(filepath.Dir(metaPath))
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+func (s Snapshots) Remove(snapshot Snapshot) error {
+	err := os.RemoveAll(s.path(snapshot.QuarterCode, snapshot.SubjectAreaCode, snapshot.CatalogNumber))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,124 @@
+package filter
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brequin/brequin/scrape/db"
+)
+
+// Empty lists match everything
+type Filter struct {
+	QuarterCodes     []string
+	SubjectAreaCodes []string
+	Since            string // Earliest quarter code, by quarter rank
+	CurrentOnly      bool
+	GeCategories     []string // Foundations, such as "SI", or categories, such as "SI/LS"; only used in listing
+
+	Now func() time.Time
+}
+
+type listFlag struct {
+	values *[]string
+}
+
+func (f listFlag) String() string {
+	if f.values == nil {
+		return ""
+	}
+	return strings.Join(*f.values, ",")
+}
+
+func (f listFlag) Set(value string) error {
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			*f.values = append(*f.values, part)
+		}
+	}
+	return nil
+}
+
+func (f *Filter) RegisterFlags(flagSet *flag.FlagSet) {
+	flagSet.Var(listFlag{&f.QuarterCodes}, "quarter", "quarter code to scrape, e.g. 24F; repeatable")
+	flagSet.Var(listFlag{&f.SubjectAreaCodes}, "subject", `subject area code to scrape, e.g. "COM SCI"; repeatable`)
+	flagSet.Func("since", "scrape only this quarter and later ones, e.g. 23F", func(value string) error {
+		if db.QuarterRank(value) == "" {
+			return fmt.Errorf("Invalid quarter code %q", value)
+		}
+		f.Since = value
+		return nil
+	})
+	flagSet.Var(listFlag{&f.GeCategories}, "ge", "GE foundation or foundation/category to list courses of, e.g. SI or SI/LS; repeatable")
+	flagSet.BoolVar(&f.CurrentOnly, "current-only", f.CurrentOnly, "scrape only the quarter currently in session")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) now() time.Time {
+	if f.Now != nil {
+		return f.Now()
+	}
+	return time.Now()
+}
+
+func (f *Filter) MatchQuarter(quarter db.Quarter) bool {
+	if len(f.QuarterCodes) > 0 && !contains(f.QuarterCodes, quarter.Code) {
+		return false
+	}
+
+	rank := db.QuarterRank(quarter.Code)
+	if f.Since != "" && (rank == "" || rank < db.QuarterRank(f.Since)) {
+		return false
+	}
+	if f.CurrentOnly && rank != db.QuarterRank(db.CurrentQuarterCode(f.now())) {
+		return false
+	}
+
+	return true
+}
+
+// Whether any quarter restriction is set
+func (f *Filter) FiltersQuarters() bool {
+	return len(f.QuarterCodes) > 0 || f.Since != "" || f.CurrentOnly
+}
+
+func (f *Filter) MatchSubjectArea(subjectArea db.SubjectArea) bool {
+	return len(f.SubjectAreaCodes) == 0 || contains(f.SubjectAreaCodes, subjectArea.Code)
+}
+
+// Whether a run under the filter scrapes a progress unit; units without a quarter or subject area match on the other alone
+func (f *Filter) MatchUnit(quarterCode, subjectAreaCode string) bool {
+	if quarterCode != "" && !f.MatchQuarter(db.Quarter{Code: quarterCode}) {
+		return false
+	}
+	return subjectAreaCode == "" || f.MatchSubjectArea(db.SubjectArea{Code: subjectAreaCode})
+}
+
+func (f *Filter) Quarters(quarters []db.Quarter) []db.Quarter {
+	var filtered []db.Quarter
+	for _, quarter := range quarters {
+		if f.MatchQuarter(quarter) {
+			filtered = append(filtered, quarter)
+		}
+	}
+	return filtered
+}
+
+func (f *Filter) SubjectAreas(subjectAreas []db.SubjectArea) []db.SubjectArea {
+	var filtered []db.SubjectArea
+	for _, subjectArea := range subjectAreas {
+		if f.MatchSubjectArea(subjectArea) {
+			filtered = append(filtered, subjectArea)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,320 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/brequin/brequin/scrape/courses"
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/details"
+	"github.com/brequin/brequin/scrape/failures"
+	"github.com/brequin/brequin/scrape/filter"
+	"github.com/brequin/brequin/scrape/progress"
+	"github.com/brequin/brequin/scrape/quarters"
+	"github.com/brequin/brequin/scrape/report"
+	"github.com/brequin/brequin/scrape/soc"
+	"github.com/brequin/brequin/scrape/subjects"
+)
+
+// Results handed from one stage to the next without a database round trip
+type State struct {
+	Client       *soc.Client
+	Database     db.Store
+	Filter       *filter.Filter
+	ProgressMode progress.Mode
+	Trackers     map[string]*progress.Tracker
+	Parameters   map[string]any // Recorded with each stage's scrape run
+	Errors       *report.Collector
+	Incremental  bool
+	RefreshGe    bool
+	Snapshots    failures.Snapshots
+
+	Quarters            []db.Quarter
+	QuarterSubjectAreas map[string][]db.SubjectArea
+	CourseCounts        courses.Counts
+	DetailsCount        int
+}
+
+type Stage struct {
+	Name      string
+	DependsOn []string
+	Run       func(ctx context.Context, state *State) error
+	Check     func(state *State) error // Rejects empty or suspicious output before dependents run
+	Counts    func(state *State) map[string]int
+	Changed   func(state *State) []string // Optional
+}
+
+var Stages = []Stage{
+	{
+		Name: "quarters",
+		Run: func(ctx context.Context, state *State) error {
+			quarters, err := quarters.Run(ctx, state.Client, state.Database)
+			state.Quarters = quarters
+			return err
+		},
+		Check: func(state *State) error {
+			if len(state.Quarters) == 0 {
+				return errors.New("No quarters scraped")
+			}
+			return nil
+		},
+		Counts: func(state *State) map[string]int {
+			return map[string]int{"quarters": len(state.Quarters)}
+		},
+	},
+	{
+		Name:      "subjects",
+		DependsOn: []string{"quarters"},
+		Run: func(ctx context.Context, state *State) error {
+			quarterSubjectAreas, err := subjects.Run(ctx, state.Client, state.Database, state.Filter.Quarters(state.Quarters), state.Trackers["subjects"], state.Errors)
+			state.QuarterSubjectAreas = quarterSubjectAreas
+			return err
+		},
+		Check: func(state *State) error {
+			quarters := state.Filter.Quarters(state.Quarters)
+			var emptyQuarterCount int
+			for _, quarter := range quarters {
+				if len(state.QuarterSubjectAreas[quarter.Code]) == 0 {
+					emptyQuarterCount++
+				}
+			}
+			// A few quarters without offerings are expected, most are not
+			if len(quarters) == 0 || emptyQuarterCount*2 > len(quarters) {
+				return fmt.Errorf("No subject areas scraped for %v quarters", emptyQuarterCount)
+			}
+			return nil
+		},
+		Counts: func(state *State) map[string]int {
+			return SubjectsCounts(state.QuarterSubjectAreas)
+		},
+	},
+	{
+		Name:      "courses",
+		DependsOn: []string{"quarters", "subjects"},
+		Run: func(ctx context.Context, state *State) error {
+			input := courses.Input{
+				Quarters:            state.Quarters,
+				SubjectAreas:        state.SubjectAreas(),
+				QuarterSubjectAreas: state.QuarterSubjectAreas,
+			}
+			counts, err := courses.Run(ctx, state.Client, state.Database, input, state.Filter, state.Trackers["courses"], state.Errors, state.Snapshots, state.Incremental, state.RefreshGe)
+			state.CourseCounts = counts
+			return err
+		},
+		Check: func(state *State) error {
+			// Nothing changing is expected of incremental runs
+			if state.CourseCounts.Courses == 0 && state.Trackers["courses"].Skipped() == 0 && !state.Incremental {
+				return errors.New("No courses scraped")
+			}
+			return nil
+		},
+		Counts: func(state *State) map[string]int {
+			return state.CourseCounts.Map()
+		},
+		Changed: func(state *State) []string {
+			return state.CourseCounts.ChangedUnits
+		},
+	},
+	{
+		Name:      "details",
+		DependsOn: []string{"subjects"}, // Equivalences name subject areas, which are looked up in the database
+		Run: func(ctx context.Context, state *State) error {
+			count, err := details.Run(ctx, state.Client, state.Database, state.Filter, state.Trackers["details"], state.Errors)
+			state.DetailsCount = count
+			return err
+		},
+		Check: func(state *State) error {
+			if state.DetailsCount == 0 && state.Trackers["details"].Skipped() == 0 {
+				return errors.New("No course details scraped")
+			}
+			return nil
+		},
+		Counts: func(state *State) map[string]int {
+			return map[string]int{"courses_details": state.DetailsCount}
+		},
+	},
+}
+
+func NewState(client *soc.Client, database db.Store, f *filter.Filter, progressMode progress.Mode, parameters map[string]any, errs *report.Collector) *State {
+	return &State{
+		Client:              client,
+		Database:            database,
+		Filter:              f,
+		ProgressMode:        progressMode,
+		Parameters:          parameters,
+		Errors:              errs,
+		Trackers:            make(map[string]*progress.Tracker),
+		QuarterSubjectAreas: make(map[string][]db.SubjectArea),
+	}
+}
+
+// Union of the subject areas offered in any quarter
+func (s *State) SubjectAreas() []db.SubjectArea {
+	seen := make(map[string]bool)
+	var subjectAreas []db.SubjectArea
+	for _, quarter := range s.Quarters {
+		for _, subjectArea := range s.QuarterSubjectAreas[quarter.Code] {
+			if !seen[subjectArea.Code] {
+				seen[subjectArea.Code] = true
+				subjectAreas = append(subjectAreas, subjectArea)
+			}
+		}
+	}
+	return subjectAreas
+}
+
+func FindStage(name string) (Stage, bool) {
+	for _, stage := range Stages {
+		if stage.Name == name {
+			return stage, true
+		}
+	}
+	return Stage{}, false
+}
+
+// Adds the dependencies of the named stages and orders them so that dependencies come first
+func Plan(names []string) ([]Stage, error) {
+	var plan []Stage
+	planned := make(map[string]bool)
+	visiting := make(map[string]bool)
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		if planned[name] {
+			return nil
+		}
+		if visiting[name] {
+			return fmt.Errorf("Stage dependency cycle at %v", name)
+		}
+		stage, found := FindStage(name)
+		if !found {
+			return fmt.Errorf("Unknown stage %v", name)
+		}
+
+		visiting[name] = true
+		for _, dependency := range stage.DependsOn {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		visiting[name] = false
+
+		planned[name] = true
+		plan = append(plan, stage)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+// Runs every stage in plan order; once a stage fails, the stages depending on it are skipped
+func Run(ctx context.Context, state *State, plan []Stage) error {
+	unlock, err := Lock(ctx, state.Database)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	failed := make(map[string]bool)
+	var errs []error
+
+	for _, stage := range plan {
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		var failedDependency string
+		for _, dependency := range stage.DependsOn {
+			if failed[dependency] {
+				failedDependency = dependency
+				break
+			}
+		}
+		if failedDependency != "" {
+			log.Printf("Skipping stage %v because stage %v failed\n", stage.Name, failedDependency)
+			failed[stage.Name] = true
+			continue
+		}
+
+		log.Printf("Running stage %v\n", stage.Name)
+
+		tracker, err := progress.Load(ctx, state.Database, stage.Name, state.ProgressMode, state.Filter)
+		if err != nil {
+			return err
+		}
+		state.Trackers[stage.Name] = tracker
+
+		database := state.Database
+		err = WithScrapeRun(ctx, database, state.Errors, stage.Name, state.Parameters, func(runDatabase db.Store) (db.RunSummary, error) {
+			state.Database = runDatabase
+			defer func() { state.Database = database }()
+
+			if err := stage.Run(ctx, state); err != nil {
+				return db.RunSummary{}, err
+			}
+			summary := db.RunSummary{Counts: stage.Counts(state)}
+			if stage.Changed != nil {
+				summary.Changed = stage.Changed(state)
+			}
+			if stage.Check != nil {
+				if err := stage.Check(state); err != nil {
+					return summary, err
+				}
+			}
+			return summary, nil
+		})
+		if err != nil {
+			log.Printf("Stage %v failed: %v\n", stage.Name, err)
+			failed[stage.Name] = true
+			errs = append(errs, fmt.Errorf("%v: %w", stage.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func SubjectsCounts(quarterSubjectAreas map[string][]db.SubjectArea) map[string]int {
+	var quarterSubjectAreaCount int
+	for _, subjectAreas := range quarterSubjectAreas {
+		quarterSubjectAreaCount += len(subjectAreas)
+	}
+	return map[string]int{"quarters": len(quarterSubjectAreas), "quarter_subject_areas": quarterSubjectAreaCount}
+}
+
+// Tags everything run writes with a new scrape run, which is closed with the returned summary and error
+// Counts the stage's errors collected during the run as its failures
+func WithScrapeRun(ctx context.Context, database db.Store, errs *report.Collector, stage string, parameters map[string]any, run func(database db.Store) (db.RunSummary, error)) error {
+	runId, err := database.StartScrapeRun(ctx, stage, parameters)
+	if err != nil {
+		return err
+	}
+
+	previousFailures := errs.CountStage(stage)
+	summary, runErr := run(database.WithRun(runId))
+	if summary.Counts == nil {
+		summary.Counts = make(map[string]int)
+	}
+	summary.Counts["failures"] = errs.CountStage(stage) - previousFailures
+
+	// Cancelled runs are still closed, recording the cancellation
+	if err := database.FinishScrapeRun(context.WithoutCancel(ctx), runId, summary, runErr); err != nil {
+		log.Printf("Unable to finish scrape run %v: %v\n", runId, err)
+	}
+	return runErr
+}
+
+const lockName = "scrape"
+
+// Taken for a whole command, or by Run for a whole pipeline, before progress is loaded, so that runs of separate processes
+// sharing a database do not overlap
+func Lock(ctx context.Context, database db.Store) (func(), error) {
+	return database.TryLock(ctx, lockName)
+}
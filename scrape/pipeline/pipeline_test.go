@@ -0,0 +1,54 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/filter"
+	"github.com/brequin/brequin/scrape/progress"
+	"github.com/brequin/brequin/scrape/report"
+)
+
+func TestRunTakesLock(t *testing.T) {
+	ctx, errs := report.NewCollector(context.Background(), report.PolicyBestEffort)
+	database := db.NewMemory()
+
+	var ran bool
+	plan := []Stage{{
+		Name: "test",
+		Run: func(ctx context.Context, state *State) error {
+			// Held for the whole run
+			if _, err := Lock(ctx, database); !errors.Is(err, db.ErrLocked) {
+				t.Errorf("Lock during Run = %v; want ErrLocked", err)
+			}
+			ran = true
+			return nil
+		},
+		Counts: func(state *State) map[string]int { return nil },
+	}}
+
+	state := NewState(nil, database, &filter.Filter{}, progress.ModeAll, nil, errs)
+	if err := Run(ctx, state, plan); err != nil {
+		t.Fatal(err)
+	}
+	if !ran {
+		t.Errorf("Stage did not run")
+	}
+
+	unlock, err := Lock(ctx, database)
+	if err != nil {
+		t.Fatalf("Lock after Run = %v; want nil", err)
+	}
+	unlock()
+
+	unlock, err = Lock(ctx, database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+	if err := Run(ctx, NewState(nil, database, &filter.Filter{}, progress.ModeAll, nil, errs), plan); !errors.Is(err, db.ErrLocked) {
+		t.Errorf("Run while locked = %v; want ErrLocked", err)
+	}
+}
@@ -0,0 +1,127 @@
+package progress
+
+import (
+	"context"
+	"log"
+	"sync"
+
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/filter"
+)
+
+type Mode int
+
+const (
+	ModeAll         Mode = iota // Scrape every unit
+	ModeResume                  // Skip completed units
+	ModeRetryFailed             // Scrape only failed units
+)
+
+type unit struct {
+	quarterCode     string
+	subjectAreaCode string
+}
+
+// Records completed and failed units of a stage; a nil tracker records nothing and skips nothing
+type Tracker struct {
+	database db.Store
+	stage    string
+	mode     Mode
+
+	mutex    sync.Mutex
+	statuses map[unit]db.ScrapeStatus
+	skipped  int
+}
+
+// Runs scraping every unit start the units f covers over, so that resuming later only skips the units they completed.
+// Progress outside f, such as that of an interrupted full crawl, is kept
+func Load(ctx context.Context, database db.Store, stage string, mode Mode, f *filter.Filter) (*Tracker, error) {
+	progress, err := database.ListScrapeProgress(ctx, stage)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == ModeAll {
+		var covered, kept []db.ScrapeProgress
+		for _, p := range progress {
+			if f.MatchUnit(p.QuarterCode, p.SubjectAreaCode) {
+				covered = append(covered, p)
+			} else {
+				kept = append(kept, p)
+			}
+		}
+		if err := database.ClearScrapeProgress(ctx, covered); err != nil {
+			return nil, err
+		}
+		progress = kept
+	}
+
+	statuses := make(map[unit]db.ScrapeStatus)
+	for _, p := range progress {
+		statuses[unit{p.QuarterCode, p.SubjectAreaCode}] = p.Status
+	}
+
+	return &Tracker{database: database, stage: stage, mode: mode, statuses: statuses}, nil
+}
+
+func (t *Tracker) Skip(quarterCode, subjectAreaCode string) bool {
+	if t == nil {
+		return false
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	status, exists := t.statuses[unit{quarterCode, subjectAreaCode}]
+	var skip bool
+	switch t.mode {
+	case ModeResume:
+		skip = exists && status == db.ScrapeStatusCompleted
+	case ModeRetryFailed:
+		skip = !exists || status != db.ScrapeStatusFailed
+	}
+	if skip {
+		t.skipped++
+	}
+	return skip
+}
+
+func (t *Tracker) Skipped() int {
+	if t == nil {
+		return 0
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.skipped
+}
+
+func (t *Tracker) record(ctx context.Context, quarterCode, subjectAreaCode string, status db.ScrapeStatus, err error) {
+	if t == nil {
+		return
+	}
+
+	t.mutex.Lock()
+	t.statuses[unit{quarterCode, subjectAreaCode}] = status
+	t.mutex.Unlock()
+
+	var errText string
+	if err != nil {
+		errText = err.Error()
+	}
+
+	p := db.ScrapeProgress{Stage: t.stage, QuarterCode: quarterCode, SubjectAreaCode: subjectAreaCode, Status: status, Error: errText}
+	// Units finished just before cancellation are still recorded
+	if err := t.database.UpsertScrapeProgress(context.WithoutCancel(ctx), p); err != nil {
+		log.Printf("Unable to record %v progress for %v %v: %v\n", t.stage, quarterCode, subjectAreaCode, err)
+	}
+}
+
+func (t *Tracker) Complete(ctx context.Context, quarterCode, subjectAreaCode string) {
+	t.record(ctx, quarterCode, subjectAreaCode, db.ScrapeStatusCompleted, nil)
+}
+
+func (t *Tracker) Fail(ctx context.Context, quarterCode, subjectAreaCode string, err error) {
+	t.record(ctx, quarterCode, subjectAreaCode, db.ScrapeStatusFailed, err)
+}
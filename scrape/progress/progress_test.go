@@ -0,0 +1,62 @@
+package progress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/filter"
+)
+
+func TestLoadClearsOnlyFilteredUnits(t *testing.T) {
+	ctx := context.Background()
+	database := db.NewMemory()
+
+	for _, p := range []db.ScrapeProgress{
+		{Stage: "courses", QuarterCode: "23F", SubjectAreaCode: "COM SCI", Status: db.ScrapeStatusCompleted},
+		{Stage: "courses", QuarterCode: "24F", SubjectAreaCode: "COM SCI", Status: db.ScrapeStatusCompleted},
+		{Stage: "courses", QuarterCode: "24F", SubjectAreaCode: "MATH", Status: db.ScrapeStatusFailed},
+		{Stage: "details", QuarterCode: "", SubjectAreaCode: "COM SCI", Status: db.ScrapeStatusCompleted},
+	} {
+		if err := database.UpsertScrapeProgress(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tracker, err := Load(ctx, database, "courses", ModeAll, &filter.Filter{Since: "24F", SubjectAreaCodes: []string{"COM SCI"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tracker.Skip("24F", "COM SCI") {
+		t.Errorf("Skip(24F, COM SCI) = true; want false")
+	}
+
+	progress, err := database.ListScrapeProgress(ctx, "courses")
+	if err != nil {
+		t.Fatal(err)
+	}
+	remaining := make(map[string]bool)
+	for _, p := range progress {
+		remaining[p.QuarterCode+" "+p.SubjectAreaCode] = true
+	}
+	if len(remaining) != 2 || !remaining["23F COM SCI"] || !remaining["24F MATH"] {
+		t.Errorf("Remaining courses progress = %v; want 23F COM SCI and 24F MATH", remaining)
+	}
+
+	// An interrupted full crawl resumes past the units it completed before the filtered run
+	tracker, err = Load(ctx, database, "courses", ModeResume, &filter.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tracker.Skip("23F", "COM SCI") {
+		t.Errorf("Skip(23F, COM SCI) = false; want true")
+	}
+
+	details, err := database.ListScrapeProgress(ctx, "details")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(details) != 1 {
+		t.Errorf("Details progress = %+v; want it untouched", details)
+	}
+}
@@ -0,0 +1,60 @@
+package quarters
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/soc"
+)
+
+const socPath = "/ro/public/soc/"
+
+func ScrapeQuarters(ctx context.Context, client *soc.Client) ([]db.Quarter, error) {
+	document, err := client.FetchDocument(ctx, client.SocUrl(socPath), nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var quarters []db.Quarter
+	var optionErr error
+
+	quarterOptions := document.Find("select#optSelectTerm").Find("option")
+	quarterOptions.EachWithBreak(func(i int, option *goquery.Selection) bool {
+		code, exists := option.Attr("value")
+		if !exists {
+			optionErr = errors.New("Unable to determine quarter code")
+			return false
+		}
+
+		name, err := option.Html()
+		if err != nil {
+			optionErr = err
+			return false
+		}
+
+		quarters = append(quarters, db.Quarter{Code: code, Name: name})
+		return true
+	})
+	if optionErr != nil {
+		return nil, optionErr
+	}
+
+	return quarters, nil
+}
+
+func Run(ctx context.Context, client *soc.Client, database db.Store) ([]db.Quarter, error) {
+	quarters, err := ScrapeQuarters(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Scraped %v quarters\n", len(quarters))
+
+	if err := database.InsertQuarters(ctx, quarters); err != nil {
+		return nil, err
+	}
+	return quarters, nil
+}
@@ -0,0 +1,173 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/brequin/brequin/scrape/soc"
+)
+
+type Kind string
+
+const (
+	KindNetwork  Kind = "network"
+	KindHttp     Kind = "http"
+	KindParse    Kind = "parse"
+	KindDatabase Kind = "database"
+)
+
+type Policy int
+
+const (
+	PolicyBestEffort Policy = iota // Keep going and report every failure at the end
+	PolicyFailFast                 // Cancel the run on the first failure
+)
+
+// A failure of one unit of work; empty codes mean the failure is not scoped to them
+type Error struct {
+	Stage           string
+	Kind            Kind
+	QuarterCode     string
+	SubjectAreaCode string
+	CatalogNumber   string
+	Err             error
+}
+
+func (e *Error) Unit() string {
+	var parts []string
+	for _, part := range []string{e.QuarterCode, e.SubjectAreaCode, e.CatalogNumber} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return "(all)"
+	}
+	return strings.Join(parts, " ")
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v %v %v: %v", e.Stage, e.Kind, e.Unit(), e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Database failures are never classified, callers know them as such
+func Classify(err error) Kind {
+	var statusError *soc.StatusError
+	if errors.As(err, &statusError) {
+		return KindHttp
+	}
+
+	var urlError *url.Error
+	var netError net.Error
+	if errors.As(err, &urlError) || errors.As(err, &netError) || errors.Is(err, soc.ErrNotCached) {
+		return KindNetwork
+	}
+
+	return KindParse
+}
+
+type Collector struct {
+	policy Policy
+	cancel context.CancelFunc
+
+	mutex  sync.Mutex
+	errors []*Error
+}
+
+// The returned context is cancelled on the first failure under PolicyFailFast
+func NewCollector(ctx context.Context, policy Policy) (context.Context, *Collector) {
+	ctx, cancel := context.WithCancel(ctx)
+	return ctx, &Collector{policy: policy, cancel: cancel}
+}
+
+func (c *Collector) Add(e *Error) {
+	c.mutex.Lock()
+	c.errors = append(c.errors, e)
+	c.mutex.Unlock()
+
+	if c.policy == PolicyFailFast {
+		c.cancel()
+	}
+}
+
+func (c *Collector) Errors() []*Error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]*Error(nil), c.errors...)
+}
+
+func (c *Collector) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return len(c.errors)
+}
+
+func (c *Collector) CountUnit(stage, quarterCode, subjectAreaCode string) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var count int
+	for _, e := range c.errors {
+		if e.Stage == stage && e.QuarterCode == quarterCode && e.SubjectAreaCode == subjectAreaCode {
+			count++
+		}
+	}
+	return count
+}
+
+// Failures across every unit of a stage
+func (c *Collector) CountStage(stage string) int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var count int
+	for _, e := range c.errors {
+		if e.Stage == stage {
+			count++
+		}
+	}
+	return count
+}
+
+// Failures grouped by stage and kind, units sorted within each group
+func (c *Collector) Summary() string {
+	errs := c.Errors()
+	sort.SliceStable(errs, func(i, j int) bool {
+		if errs[i].Stage != errs[j].Stage {
+			return errs[i].Stage < errs[j].Stage
+		}
+		if errs[i].Kind != errs[j].Kind {
+			return errs[i].Kind < errs[j].Kind
+		}
+		return errs[i].Unit() < errs[j].Unit()
+	})
+
+	var summaryBuilder strings.Builder
+	fmt.Fprintf(&summaryBuilder, "%v failures\n", len(errs))
+	for i, e := range errs {
+		if i == 0 || e.Stage != errs[i-1].Stage || e.Kind != errs[i-1].Kind {
+			var groupCount int
+			for _, other := range errs[i:] {
+				if other.Stage != e.Stage || other.Kind != e.Kind {
+					break
+				}
+				groupCount++
+			}
+			fmt.Fprintf(&summaryBuilder, "%v %v: %v\n", e.Stage, e.Kind, groupCount)
+		}
+		fmt.Fprintf(&summaryBuilder, "  %v: %v\n", e.Unit(), e.Err)
+	}
+	return summaryBuilder.String()
+}
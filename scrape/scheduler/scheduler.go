@@ -0,0 +1,205 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type JobStatus struct {
+	Name        string     `json:"name"`
+	Interval    string     `json:"interval"`
+	Running     bool       `json:"running"`
+	NextRun     time.Time  `json:"next_run"`
+	LastStarted *time.Time `json:"last_started,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+	LastFailure *time.Time `json:"last_failure,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// Runs jobs one at a time, so no two runs of one scheduler overlap; jobs falling due during a run wait for it. Runs of other processes are kept out by the pipeline's lock
+type Scheduler struct {
+	Jobs   []Job
+	Jitter time.Duration // Maximum random delay added to each run, so jobs with equal intervals do not all run back to back
+
+	mutex     sync.Mutex
+	startedAt time.Time
+	statuses  map[string]*JobStatus
+}
+
+func (s *Scheduler) init() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.statuses != nil {
+		return
+	}
+	s.startedAt = time.Now()
+	s.statuses = make(map[string]*JobStatus)
+	for _, job := range s.Jobs {
+		s.statuses[job.Name] = &JobStatus{Name: job.Name, Interval: job.Interval.String()}
+	}
+}
+
+func (s *Scheduler) jitter() time.Duration {
+	if s.Jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(s.Jitter)))
+}
+
+// Seeds a job's last success, such as from an earlier process, so it is not rerun on start
+func (s *Scheduler) SetLastSuccess(name string, t time.Time) {
+	s.init()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if status, exists := s.statuses[name]; exists {
+		status.LastSuccess = &t
+	}
+}
+
+// Runs until ctx is cancelled, letting any run in progress see the cancellation
+func (s *Scheduler) Run(ctx context.Context) error {
+	s.init()
+
+	jobs := make(map[string]Job)
+	s.mutex.Lock()
+	for _, job := range s.Jobs {
+		jobs[job.Name] = job
+		status := s.statuses[job.Name]
+		status.NextRun = time.Now().Add(s.jitter())
+		if status.LastSuccess != nil {
+			status.NextRun = status.LastSuccess.Add(job.Interval + s.jitter())
+		}
+	}
+	s.mutex.Unlock()
+
+	for {
+		next := s.nextJob()
+		if next == "" {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+
+		s.mutex.Lock()
+		wait := time.Until(s.statuses[next].NextRun)
+		s.mutex.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		s.run(ctx, jobs[next])
+	}
+}
+
+func (s *Scheduler) nextJob() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var next string
+	for _, job := range s.Jobs {
+		if next == "" || s.statuses[job.Name].NextRun.Before(s.statuses[next].NextRun) {
+			next = job.Name
+		}
+	}
+	return next
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	startedAt := time.Now()
+	s.mutex.Lock()
+	status := s.statuses[job.Name]
+	status.Running = true
+	status.LastStarted = &startedAt
+	s.mutex.Unlock()
+
+	log.Printf("Running scheduled %v\n", job.Name)
+	err := job.Run(ctx)
+
+	finishedAt := time.Now()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	status.Running = false
+	// Intervals count from the start of a run, so long runs do not drift the schedule
+	status.NextRun = startedAt.Add(job.Interval + s.jitter())
+	if status.NextRun.Before(finishedAt) {
+		status.NextRun = finishedAt
+	}
+	if err != nil {
+		log.Printf("Scheduled %v failed: %v\n", job.Name, err)
+		// Failed runs are retried sooner than successful ones are repeated
+		status.NextRun = finishedAt.Add(job.Interval/4 + s.jitter())
+		status.LastFailure = &finishedAt
+		status.LastError = err.Error()
+		return
+	}
+	status.LastSuccess = &finishedAt
+	status.LastError = ""
+}
+
+func (s *Scheduler) Statuses() []JobStatus {
+	s.init()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var statuses []JobStatus
+	for _, job := range s.Jobs {
+		statuses = append(statuses, *s.statuses[job.Name])
+	}
+	return statuses
+}
+
+// A job is overdue once its last success, or the scheduler's start if it never succeeded, is more than two intervals old
+func (s *Scheduler) Healthy(now time.Time) bool {
+	s.init()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, job := range s.Jobs {
+		since := s.startedAt
+		if lastSuccess := s.statuses[job.Name].LastSuccess; lastSuccess != nil {
+			since = *lastSuccess
+		}
+		if now.Sub(since) > 2*job.Interval+s.Jitter {
+			return false
+		}
+	}
+	return true
+}
+
+// Serves /healthz, which fails once a job is overdue, and /status with every job's status as JSON
+func (s *Scheduler) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		if !s.Healthy(time.Now()) {
+			http.Error(w, "overdue", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.Statuses())
+	})
+	return mux
+}
@@ -0,0 +1,61 @@
+package soc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+var ErrNotCached = errors.New("Response not in cache")
+
+// Raw response bodies keyed by the hash of the full request URL, query included
+type Cache struct {
+	Dir string
+}
+
+func (c Cache) Enabled() bool {
+	return c.Dir != ""
+}
+
+func (c Cache) Key(rawUrl string) string {
+	sum := sha256.Sum256([]byte(rawUrl))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c Cache) path(key string) string {
+	return filepath.Join(c.Dir, key[:2], key)
+}
+
+func (c Cache) Get(key string) ([]byte, error) {
+	content, err := os.ReadFile(c.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotCached
+	}
+	return content, err
+}
+
+func (c Cache) Put(key string, content []byte) error {
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	// Rename so concurrent readers never see a partial body
+	file, err := os.CreateTemp(filepath.Dir(path), key+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
@@ -0,0 +1,186 @@
+package soc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const DefaultSocBaseUrl = "https://sa.ucla.edu"
+const DefaultApiBaseUrl = "https://api.ucla.edu"
+
+type Doer interface {
+	Do(request *http.Request) (*http.Response, error)
+}
+
+type Client struct {
+	SocBaseUrl string
+	ApiBaseUrl string
+	Header     http.Header
+	Doer       Doer
+	Limiter    *Limiter
+	Retry      RetryPolicy
+	Cache      Cache
+	Offline    bool
+}
+
+func NewClient() *Client {
+	return &Client{
+		SocBaseUrl: DefaultSocBaseUrl,
+		ApiBaseUrl: DefaultApiBaseUrl,
+		Header:     http.Header{},
+		Doer:       http.DefaultClient,
+		Limiter:    NewLimiter(DefaultRequestsPerSecond, DefaultMaxInFlight),
+		Retry:      DefaultRetryPolicy,
+	}
+}
+
+// Base URLs may be overridden to point at a mirror or a local stand-in server
+func NewClientFromEnv() *Client {
+	client := NewClient()
+	if socBaseUrl := os.Getenv("SOC_BASE_URL"); socBaseUrl != "" {
+		client.SocBaseUrl = socBaseUrl
+	}
+	if apiBaseUrl := os.Getenv("API_BASE_URL"); apiBaseUrl != "" {
+		client.ApiBaseUrl = apiBaseUrl
+	}
+	if requestsPerSecond, err := strconv.ParseFloat(os.Getenv("SCRAPE_REQUESTS_PER_SECOND"), 64); err == nil {
+		client.Limiter.RequestsPerSecond = requestsPerSecond
+	}
+	if maxInFlight, err := strconv.Atoi(os.Getenv("SCRAPE_MAX_IN_FLIGHT")); err == nil {
+		client.Limiter.MaxInFlight = maxInFlight
+	}
+	if maxAttempts, err := strconv.Atoi(os.Getenv("SCRAPE_MAX_ATTEMPTS")); err == nil {
+		client.Retry.MaxAttempts = maxAttempts
+	}
+	client.Cache.Dir = os.Getenv("SCRAPE_CACHE_DIR")
+	return client
+}
+
+func (c *Client) RegisterFlags(flagSet *flag.FlagSet) {
+	flagSet.StringVar(&c.Cache.Dir, "cache-dir", c.Cache.Dir, "directory in which raw responses are cached")
+	flagSet.BoolVar(&c.Offline, "offline", c.Offline, "read responses only from the cache, never the network")
+}
+
+func (c *Client) SocUrl(path string) string {
+	return strings.TrimSuffix(c.SocBaseUrl, "/") + path
+}
+
+func (c *Client) ApiUrl(path string) string {
+	return strings.TrimSuffix(c.ApiBaseUrl, "/") + path
+}
+
+func (c *Client) NewRequest(ctx context.Context, rawUrl string, query url.Values, xhr bool) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, "GET", rawUrl, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(query) > 0 {
+		requestQuery := request.URL.Query()
+		for key, values := range query {
+			for _, value := range values {
+				requestQuery.Add(key, value)
+			}
+		}
+		request.URL.RawQuery = requestQuery.Encode()
+	}
+
+	for key, values := range c.Header {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+
+	// Required by endpoints serving partial pages
+	if xhr {
+		request.Header.Add("X-Requested-With", "XMLHttpRequest")
+	}
+
+	return request, nil
+}
+
+func (c *Client) Fetch(ctx context.Context, rawUrl string, query url.Values, xhr bool) ([]byte, error) {
+	request, err := c.NewRequest(ctx, rawUrl, query, xhr)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Offline {
+		if !c.Cache.Enabled() {
+			return nil, errors.New("Offline mode requires a cache directory")
+		}
+		return c.Cache.Get(c.Cache.Key(request.URL.String()))
+	}
+
+	for attempt := 0; ; attempt++ {
+		content, err := c.fetchOnce(request)
+		if err == nil {
+			if c.Cache.Enabled() {
+				if err := c.Cache.Put(c.Cache.Key(request.URL.String()), content); err != nil {
+					return nil, err
+				}
+			}
+			return content, nil
+		}
+		if attempt+1 >= c.Retry.MaxAttempts || !IsTransient(err) || ctx.Err() != nil {
+			return nil, err
+		}
+
+		delay := c.Retry.Delay(attempt, err)
+		if c.Retry.MaxRetryAfter > 0 && delay > c.Retry.MaxRetryAfter {
+			return nil, fmt.Errorf("Asked to retry after %v, longer than the %v allowed: %w", delay, c.Retry.MaxRetryAfter, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) fetchOnce(request *http.Request) ([]byte, error) {
+	if c.Limiter != nil {
+		release, err := c.Limiter.Acquire(request.Context(), request.URL.Host)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	response, err := c.Doer.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		io.Copy(io.Discard, response.Body)
+		return nil, NewStatusError(request, response)
+	}
+
+	return io.ReadAll(response.Body)
+}
+
+func (c *Client) FetchDocument(ctx context.Context, rawUrl string, query url.Values, xhr bool) (*goquery.Document, error) {
+	content, err := c.Fetch(ctx, rawUrl, query, xhr)
+	if err != nil {
+		return nil, err
+	}
+
+	return goquery.NewDocumentFromReader(bytes.NewReader(content))
+}
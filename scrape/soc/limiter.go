@@ -0,0 +1,96 @@
+package soc
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const DefaultRequestsPerSecond = 10
+const DefaultMaxInFlight = 8
+
+// Limits are applied separately to each host
+type Limiter struct {
+	RequestsPerSecond float64
+	MaxInFlight       int
+
+	mutex sync.Mutex
+	hosts map[string]*hostLimiter
+}
+
+type hostLimiter struct {
+	inFlight chan struct{}
+
+	mutex sync.Mutex
+	next  time.Time
+}
+
+func NewLimiter(requestsPerSecond float64, maxInFlight int) *Limiter {
+	return &Limiter{
+		RequestsPerSecond: requestsPerSecond,
+		MaxInFlight:       maxInFlight,
+		hosts:             make(map[string]*hostLimiter),
+	}
+}
+
+func (l *Limiter) host(host string) *hostLimiter {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.hosts == nil {
+		l.hosts = make(map[string]*hostLimiter)
+	}
+
+	h, exists := l.hosts[host]
+	if !exists {
+		h = &hostLimiter{}
+		if l.MaxInFlight > 0 {
+			h.inFlight = make(chan struct{}, l.MaxInFlight)
+		}
+		l.hosts[host] = h
+	}
+	return h
+}
+
+// Blocks until a request to host may start; the returned function must be called once it is done
+func (l *Limiter) Acquire(ctx context.Context, host string) (func(), error) {
+	h := l.host(host)
+
+	if h.inFlight != nil {
+		select {
+		case h.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	release := func() {
+		if h.inFlight != nil {
+			<-h.inFlight
+		}
+	}
+
+	if l.RequestsPerSecond > 0 {
+		interval := time.Duration(float64(time.Second) / l.RequestsPerSecond)
+
+		h.mutex.Lock()
+		now := time.Now()
+		start := h.next
+		if start.Before(now) {
+			start = now
+		}
+		h.next = start.Add(interval)
+		h.mutex.Unlock()
+
+		timer := time.NewTimer(start.Sub(now))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			release()
+			return nil, ctx.Err()
+		}
+	}
+
+	return release, nil
+}
@@ -0,0 +1,92 @@
+package soc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration // Of backoff; servers' Retry-After is waited out in full
+	// Requests asked to retry after longer than this fail instead of waiting
+	MaxRetryAfter time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 5, BaseDelay: 500 * time.Millisecond, MaxDelay: 30 * time.Second, MaxRetryAfter: 5 * time.Minute}
+
+type StatusError struct {
+	Url        string
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("Unexpected status %v from %v", e.StatusCode, e.Url)
+}
+
+func NewStatusError(request *http.Request, response *http.Response) *StatusError {
+	return &StatusError{
+		Url:        request.URL.String(),
+		StatusCode: response.StatusCode,
+		RetryAfter: ParseRetryAfter(response.Header.Get("Retry-After")),
+	}
+}
+
+// Accepts both delay-seconds and HTTP-date forms
+func ParseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+	return 0
+}
+
+func IsTransient(err error) bool {
+	var statusError *StatusError
+	if errors.As(err, &statusError) {
+		return statusError.StatusCode == http.StatusTooManyRequests || statusError.StatusCode >= 500
+	}
+
+	var netError net.Error
+	if errors.As(err, &netError) && netError.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
+
+// Exponential backoff with full jitter, unless the server asked for a specific delay
+func (p RetryPolicy) Delay(attempt int, err error) time.Duration {
+	var statusError *StatusError
+	if errors.As(err, &statusError) && statusError.RetryAfter > 0 {
+		return statusError.RetryAfter
+	}
+
+	backoff := p.BaseDelay << attempt
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(backoff)))
+}
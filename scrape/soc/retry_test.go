@@ -0,0 +1,84 @@
+package soc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&StatusError{StatusCode: http.StatusTooManyRequests}, true},
+		{&StatusError{StatusCode: http.StatusInternalServerError}, true},
+		{&StatusError{StatusCode: http.StatusBadGateway}, true},
+		{&StatusError{StatusCode: http.StatusServiceUnavailable}, true},
+		{&StatusError{StatusCode: http.StatusNotFound}, false},
+		{&StatusError{StatusCode: http.StatusForbidden}, false},
+		{fmt.Errorf("read: %w", syscall.ECONNRESET), true},
+		{fmt.Errorf("dial: %w", syscall.ECONNREFUSED), true},
+		{fmt.Errorf("write: %w", syscall.EPIPE), true},
+		{io.ErrUnexpectedEOF, true},
+		{context.DeadlineExceeded, true},
+		{errors.New("Unable to parse"), false},
+	}
+
+	for _, test := range tests {
+		if got := IsTransient(test.err); got != test.want {
+			t.Errorf("IsTransient(%v) = %v; want %v", test.err, got, test.want)
+		}
+	}
+}
+
+// Status codes to respond with in turn, then 200
+type statusDoer struct {
+	statuses   []int
+	retryAfter string
+	requests   int
+}
+
+func (d *statusDoer) Do(request *http.Request) (*http.Response, error) {
+	d.requests++
+	status := http.StatusOK
+	if d.requests <= len(d.statuses) {
+		status = d.statuses[d.requests-1]
+	}
+	header := http.Header{}
+	if d.retryAfter != "" {
+		header.Set("Retry-After", d.retryAfter)
+	}
+	return &http.Response{StatusCode: status, Header: header, Body: http.NoBody, Request: request}, nil
+}
+
+func TestFetchRetryAfter(t *testing.T) {
+	client := NewClient()
+	client.Limiter = nil
+	client.Retry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, MaxRetryAfter: 2 * time.Second}
+
+	// Waited out in full, though longer than MaxDelay
+	doer := &statusDoer{statuses: []int{http.StatusTooManyRequests}, retryAfter: "1"}
+	client.Doer = doer
+	start := time.Now()
+	if _, err := client.Fetch(context.Background(), "https://soc.test/", nil, false); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); doer.requests != 2 || elapsed < time.Second {
+		t.Errorf("Sent %v requests in %v; want 2 after waiting out Retry-After", doer.requests, elapsed)
+	}
+
+	// Longer than allowed fails at once, keeping the status
+	doer = &statusDoer{statuses: []int{http.StatusServiceUnavailable}, retryAfter: "3600"}
+	client.Doer = doer
+	_, err := client.Fetch(context.Background(), "https://soc.test/", nil, false)
+	var statusError *StatusError
+	if !errors.As(err, &statusError) || doer.requests != 1 {
+		t.Errorf("Fetch = %v after %v requests; want the status error after 1", err, doer.requests)
+	}
+}
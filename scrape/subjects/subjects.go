@@ -0,0 +1,135 @@
+package subjects
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"html"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/brequin/brequin/scrape/db"
+	"github.com/brequin/brequin/scrape/progress"
+	"github.com/brequin/brequin/scrape/report"
+	"github.com/brequin/brequin/scrape/soc"
+)
+
+const subjectSearchPath = "/ro/ClassSearch/Public/Search/GetSimpleSearchData"
+
+type SubjectAreaOption struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+var searchPanelSetupPattern = regexp.MustCompile(`SearchPanelSetup\('(\[\{.*\}\])'`)
+
+// Pages without subject area options, such as unexpected pages read from the cache, are parse errors
+func ParseSubjectAreas(content []byte) ([]db.SubjectArea, error) {
+	match := searchPanelSetupPattern.FindSubmatch(content)
+	if match == nil {
+		return nil, errors.New("Unable to find subject area options")
+	}
+	encodedOptions := []byte(html.UnescapeString(string(match[1])))
+
+	var subjectAreaOptions []SubjectAreaOption
+	if err := json.Unmarshal(encodedOptions, &subjectAreaOptions); err != nil {
+		return nil, err
+	}
+
+	var subjectAreas []db.SubjectArea
+	for _, subjectAreaOption := range subjectAreaOptions {
+		code := strings.TrimSpace(subjectAreaOption.Value)
+
+		labelCode := "(" + code + ")"
+		name := strings.TrimSpace(strings.ReplaceAll(subjectAreaOption.Label, labelCode, ""))
+
+		subjectAreas = append(subjectAreas, db.SubjectArea{Code: code, Name: name})
+	}
+
+	return subjectAreas, nil
+}
+
+func ScrapeSubjectAreas(ctx context.Context, client *soc.Client, quarterCode string) ([]db.SubjectArea, error) {
+	query := url.Values{}
+	query.Add("term_cd", quarterCode)
+	query.Add("search_type", "subject")
+
+	content, err := client.Fetch(ctx, client.SocUrl(subjectSearchPath), query, true)
+	if err != nil {
+		return nil, err
+	}
+
+	subjectAreas, err := ParseSubjectAreas(content)
+	if err != nil {
+		return nil, err
+	}
+	return subjectAreas, nil
+}
+
+// Returns the subject areas offered in each quarter, keyed by quarter code
+func Run(ctx context.Context, client *soc.Client, database db.Store, quarters []db.Quarter, tracker *progress.Tracker, errs *report.Collector) (map[string][]db.SubjectArea, error) {
+	quarterSubjectAreas := make(map[string][]db.SubjectArea)
+	var quarterSubjectAreasMutex sync.Mutex
+
+	var wg sync.WaitGroup
+	for _, quarter := range quarters {
+		if ctx.Err() != nil {
+			break
+		}
+
+		// Skipped quarters still hand their previously scraped subject areas downstream
+		if tracker.Skip(quarter.Code, "") {
+			subjectAreas, err := database.ListQuarterSubjectAreas(ctx, quarter)
+			if err != nil {
+				return nil, err
+			}
+			quarterSubjectAreas[quarter.Code] = subjectAreas
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(q db.Quarter) {
+			defer wg.Done()
+
+			subjectAreas, err := ScrapeSubjectAreas(ctx, client, q.Code)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				errs.Add(&report.Error{Stage: "subjects", Kind: report.Classify(err), QuarterCode: q.Code, Err: err})
+				tracker.Fail(ctx, q.Code, "", err)
+				return
+			}
+
+			log.Printf("%v: Scraped %v subject areas\n", q.Code, len(subjectAreas))
+
+			err = database.InTx(ctx, func(tx db.Store) error {
+				if err := tx.InsertSubjectAreas(ctx, subjectAreas); err != nil {
+					return err
+				}
+				return tx.InsertQuarterSubjectAreas(ctx, q, subjectAreas)
+			})
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				errs.Add(&report.Error{Stage: "subjects", Kind: report.KindDatabase, QuarterCode: q.Code, Err: err})
+				tracker.Fail(ctx, q.Code, "", err)
+				return
+			}
+
+			tracker.Complete(ctx, q.Code, "")
+
+			quarterSubjectAreasMutex.Lock()
+			quarterSubjectAreas[q.Code] = subjectAreas
+			quarterSubjectAreasMutex.Unlock()
+		}(quarter)
+	}
+	wg.Wait()
+
+	return quarterSubjectAreas, ctx.Err()
+}